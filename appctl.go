@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// appctl runs an ovs-appctl command against ovs-vswitchd and returns its
+// output. The command is abandoned when ctx is done.
+type appctl interface {
+	run(ctx context.Context, command string, args ...string) (string, error)
+}
+
+// execAppctl runs commands by forking the ovs-appctl binary.
+type execAppctl struct {
+	path string
+}
+
+func (ctl execAppctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, ctl.path, append([]string{command}, args...)...)
+	// Don't wait on children of ovs-appctl still holding the output pipes
+	// once it has been killed.
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// execOfctl runs ovs-ofctl commands against a bridge. It negotiates up to
+// OpenFlow 1.3, which meter-stats needs, when the bridge allows it, and
+// prints port names, which ovs-ofctl only does by default on a terminal.
+type execOfctl struct {
+	path string
+}
+
+func (ctl execOfctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	// Options go before the command, not every getopt permutes them.
+	options := []string{"--names", "-O", "OpenFlow10,OpenFlow11,OpenFlow12,OpenFlow13", command}
+	cmd := exec.CommandContext(ctx, ctl.path, append(options, args...)...)
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// timeoutAppctl bounds each command by timeout and counts the commands that
+// didn't complete in time.
+type timeoutAppctl struct {
+	appctl   appctl
+	timeout  time.Duration
+	timeouts *prometheus.CounterVec
+}
+
+func (ctl timeoutAppctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ctl.timeout)
+	defer cancel()
+
+	output, err := ctl.appctl.run(ctx, command, args...)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		ctl.timeouts.WithLabelValues(command).Inc()
+		return output, fmt.Errorf("%s timed out: %w", command, err)
+	}
+	return output, err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeAppctl answers commands, joined with their arguments, from a map.
+type fakeAppctl map[string]string
+
+func (ctl fakeAppctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	output, ok := ctl[strings.Join(append([]string{command}, args...), " ")]
+	if !ok {
+		return "", errors.New("unknown command")
+	}
+	return output, nil
+}
+
+func Test_timeoutAppctl(t *testing.T) {
+	timeouts := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "timeouts"}, []string{"command"})
+	// sleep stands in for an ovs-appctl that never returns.
+	ctl := timeoutAppctl{appctl: execAppctl{path: "sleep"}, timeout: 50 * time.Millisecond, timeouts: timeouts}
+
+	start := time.Now()
+	if _, err := ctl.run(context.Background(), "10"); err == nil {
+		t.Error("Expected the command to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Command returned after %s, expected it to be killed", elapsed)
+	}
+	if v := testutil.ToFloat64(timeouts.WithLabelValues("10")); v != 1 {
+		t.Errorf("Expected 1 timeout, got %v", v)
+	}
+}
+
+func Test_execOfctl(t *testing.T) {
+	// echo stands in for ovs-ofctl to show its arguments.
+	ctl := execOfctl{path: "echo"}
+
+	output, err := ctl.run(context.Background(), "meter-stats", "br0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "--names -O OpenFlow10,OpenFlow11,OpenFlow12,OpenFlow13 meter-stats br0\n"
+	if output != expected {
+		t.Errorf("Outputs are different: got %q, want %q", output, expected)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bfdStates encodes BFD session states like the State field of RFC 5880.
+var bfdStates = map[string]float64{
+	"admin_down": 0,
+	"down":       1,
+	"init":       2,
+	"up":         3,
+}
+
+// bfdDiagnostics encodes BFD diagnostics like the Diag field of RFC 5880.
+var bfdDiagnostics = map[string]float64{
+	"No Diagnostic":                  0,
+	"Control Detection Time Expired": 1,
+	"Echo Function Failed":           2,
+	"Neighbor Signaled Session Down": 3,
+	"Forwarding Plane Reset":         4,
+	"Path Down":                      5,
+	"Concatenated Path Down":         6,
+	"Administratively Down":          7,
+	"Reverse Concatenated Path Down": 8,
+}
+
+// BFDMetric holds the BFD session of an interface from bfd/show.
+type BFDMetric struct {
+	Interface string
+	// Values are keyed by the text before the colon of each line, e.g.
+	// "Local Session State", with states and diagnostics encoded by
+	// bfdStates and bfdDiagnostics and intervals in seconds. "detect
+	// interval" is the time without control packets after which the session
+	// goes down.
+	Values map[string]float64
+}
+
+// CFMMetric holds the CFM state of an interface from cfm/show.
+type CFMMetric struct {
+	Interface string
+	// Values hold "fault", "average health", "opstate", "remote_opstate",
+	// "interval" in seconds and "remote mps".
+	Values map[string]float64
+}
+
+// bfdSamples returns the values of a bfd/show or cfm/show registry metric.
+func (metrics *OvsMetric) bfdSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	switch spec.command {
+	case bfdCommand:
+		for _, session := range metrics.BFDs {
+			if v, ok := session.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{session.Interface}})
+			}
+		}
+	case cfmCommand:
+		for _, cfm := range metrics.CFMs {
+			if v, ok := cfm.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{cfm.Interface}})
+			}
+		}
+	}
+	return samples
+}
+
+func parseBFD(metrics *OvsMetric, bfdShow string) {
+	lineRegexp := regexp.MustCompile(`(?m)^[ \t]+([\w ]+): (.*?)[ \t]*$`)
+	// e.g. "Approx 1000ms" or "1000ms"
+	intervalRegexp := regexp.MustCompile(`^(?:Approx )?(\d+)ms$`)
+
+	metrics.BFDs = nil
+	interfaces, bodies := splitDashedSections(bfdShow)
+	for i, body := range bodies {
+		session := BFDMetric{Interface: interfaces[i], Values: make(map[string]float64)}
+
+		for _, match := range lineRegexp.FindAllStringSubmatch(body, -1) {
+			source, value := match[1], match[2]
+			switch {
+			case strings.HasSuffix(source, "Session State"):
+				state, ok := bfdStates[value]
+				if !ok {
+					metrics.parseFailure(bfdCommand, source)
+					continue
+				}
+				session.Values[source] = state
+			case strings.HasSuffix(source, "Diagnostic"):
+				diagnostic, ok := bfdDiagnostics[value]
+				if !ok {
+					metrics.parseFailure(bfdCommand, source)
+					continue
+				}
+				session.Values[source] = diagnostic
+			case value == "true" || value == "false":
+				session.Values[source] = boolValue(value == "true")
+			case intervalRegexp.MatchString(value):
+				ms, err := strconv.ParseFloat(intervalRegexp.FindStringSubmatch(value)[1], 64)
+				if err != nil {
+					metrics.parseFailure(bfdCommand, source)
+					continue
+				}
+				session.Values[source] = ms / 1000
+			default:
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					session.Values[source] = v
+				}
+			}
+		}
+
+		// The remote detect multiplier times the agreed rx interval, as
+		// RFC 5880 section 6.8.4 computes the detection time.
+		rx, rxOk := session.Values["RX Interval"]
+		multiplier, multiplierOk := session.Values["Remote Detect Multiplier"]
+		if rxOk && multiplierOk {
+			session.Values["detect interval"] = rx * multiplier
+		}
+
+		metrics.BFDs = append(metrics.BFDs, session)
+	}
+}
+
+func parseCFM(metrics *OvsMetric, cfmShow string) {
+	faultRegexp := regexp.MustCompile(`(?m)^[ \t]+fault: `)
+	healthRegexp := regexp.MustCompile(`(?m)^[ \t]+average health: (\d+)`)
+	opstateRegexp := regexp.MustCompile(`(?m)^[ \t]+(opstate|remote_opstate): (up|down)`)
+	intervalRegexp := regexp.MustCompile(`(?m)^[ \t]+interval: (\d+)ms`)
+	remoteRegexp := regexp.MustCompile(`(?m)^Remote MPID \d+`)
+
+	metrics.CFMs = nil
+	interfaces, bodies := splitDashedSections(cfmShow)
+	for i, body := range bodies {
+		cfm := CFMMetric{Interface: interfaces[i], Values: make(map[string]float64)}
+
+		// The remote MPs follow the local MP, with their own opstate.
+		local := body
+		if loc := remoteRegexp.FindStringIndex(body); loc != nil {
+			local = body[:loc[0]]
+		}
+
+		cfm.Values["fault"] = boolValue(faultRegexp.MatchString(local))
+		if match := healthRegexp.FindStringSubmatch(local); match != nil {
+			v, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				metrics.parseFailure(cfmCommand, "average health")
+			} else {
+				cfm.Values["average health"] = v
+			}
+		}
+		for _, match := range opstateRegexp.FindAllStringSubmatch(local, -1) {
+			cfm.Values[match[1]] = boolValue(match[2] == "up")
+		}
+		if match := intervalRegexp.FindStringSubmatch(local); match != nil {
+			ms, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				metrics.parseFailure(cfmCommand, "interval")
+			} else {
+				cfm.Values["interval"] = ms / 1000
+			}
+		}
+		cfm.Values["remote mps"] = float64(len(remoteRegexp.FindAllString(body, -1)))
+
+		metrics.CFMs = append(metrics.CFMs, cfm)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseBFD(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `---- vxlan0 ----
+	Forwarding: true
+	Detect Multiplier: 3
+	Concatenated Path Down: false
+	TX Interval: Approx 1000ms
+	RX Interval: Approx 300ms
+	Detect Time: now +843ms
+	Next TX Time: now +627ms
+	Last TX Time: now -339ms
+
+	Local Flags: none
+	Local Session State: up
+	Local Diagnostic: No Diagnostic
+	Local Discriminator: 0x7f1d3e02
+	Local Minimum TX Interval: 1000ms
+	Local Minimum RX Interval: 300ms
+
+	Remote Flags: none
+	Remote Session State: up
+	Remote Diagnostic: No Diagnostic
+	Remote Discriminator: 0x3c5ba2e1
+	Remote Minimum TX Interval: 300ms
+	Remote Minimum RX Interval: 1000ms
+	Remote Detect Multiplier: 5
+---- vxlan1 ----
+	Forwarding: false
+	Detect Multiplier: 3
+	Concatenated Path Down: false
+	TX Interval: Approx 1000ms
+	RX Interval: Approx 1000ms
+
+	Local Flags: none
+	Local Session State: down
+	Local Diagnostic: Control Detection Time Expired
+
+	Remote Flags: none
+	Remote Session State: init
+	Remote Diagnostic: Neighbor Signaled Session Down
+	Remote Detect Multiplier: 3
+`,
+			metric: OvsMetric{
+				BFDs: []BFDMetric{
+					{
+						Interface: "vxlan0",
+						Values: map[string]float64{
+							"Forwarding": 1, "Detect Multiplier": 3, "Concatenated Path Down": 0,
+							"TX Interval": 1, "RX Interval": 0.3, "detect interval": 1.5,
+							"Local Session State": 3, "Local Diagnostic": 0,
+							"Local Minimum TX Interval": 1, "Local Minimum RX Interval": 0.3,
+							"Remote Session State": 3, "Remote Diagnostic": 0,
+							"Remote Minimum TX Interval": 0.3, "Remote Minimum RX Interval": 1,
+							"Remote Detect Multiplier": 5,
+						},
+					},
+					{
+						Interface: "vxlan1",
+						Values: map[string]float64{
+							"Forwarding": 0, "Detect Multiplier": 3, "Concatenated Path Down": 0,
+							"TX Interval": 1, "RX Interval": 1, "detect interval": 3,
+							"Local Session State": 1, "Local Diagnostic": 1,
+							"Remote Session State": 2, "Remote Diagnostic": 3,
+							"Remote Detect Multiplier": 3,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown state",
+			output: `---- vxlan0 ----
+	Local Session State: bogus
+`,
+			metric: OvsMetric{
+				BFDs:          []BFDMetric{{Interface: "vxlan0", Values: map[string]float64{}}},
+				ParseFailures: []string{"ovsdp_bfd_local_state"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseBFD(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseCFM(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `---- eth0 ----
+MPID 1: extended
+	average health: 100
+	opstate: up
+	remote_opstate: up
+	interval: 1000ms
+	next CCM tx: 478ms
+	next fault check: 1628ms
+
+Remote MPID 2
+	recv since check: true
+	opstate: up
+---- eth1 ----
+MPID 3:
+	fault: recv
+	average health: undefined
+	opstate: down
+	remote_opstate: down
+	interval: 300ms
+	next CCM tx: 100ms
+	next fault check: 700ms
+
+`,
+			metric: OvsMetric{
+				CFMs: []CFMMetric{
+					{
+						Interface: "eth0",
+						Values:    map[string]float64{"fault": 0, "average health": 100, "opstate": 1, "remote_opstate": 1, "interval": 1, "remote mps": 1},
+					},
+					{
+						Interface: "eth1",
+						Values:    map[string]float64{"fault": 1, "opstate": 0, "remote_opstate": 0, "interval": 0.3, "remote mps": 0},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseCFM(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
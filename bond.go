@@ -0,0 +1,198 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BondMetric holds a bond from bond/show.
+type BondMetric struct {
+	Bond    string
+	Mode    string
+	Members []BondMemberMetric
+}
+
+// BondMemberMetric is a member of a bond, called slave before OVS 2.15.
+type BondMemberMetric struct {
+	Member string
+	// Values hold "enabled", "may_enable" and "active".
+	Values map[string]float64
+	Hashes []BondHashMetric
+}
+
+// BondHashMetric is a hash bucket of a bond assigned to a member, with the
+// bytes it recently sent.
+type BondHashMetric struct {
+	Hash string
+	Load float64
+}
+
+// LACPMetric holds the LACP state of a bond from lacp/show.
+type LACPMetric struct {
+	Bond string
+	// Values hold "negotiated".
+	Values  map[string]float64
+	Members []LACPMemberMetric
+}
+
+// LACPMemberMetric is a member of an LACP bond with its partner as printed,
+// all zeros when no partner was seen.
+type LACPMemberMetric struct {
+	Member        string
+	PartnerSysID  string
+	PartnerPortID string
+	PartnerKey    string
+	// Values hold "current" and "attached".
+	Values map[string]float64
+}
+
+// bondSamples returns the values of a bond/show registry metric.
+func (metrics *OvsMetric) bondSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, bond := range metrics.Bonds {
+		if spec.source == "mode" && bond.Mode != "" {
+			samples = append(samples, Sample{Value: 1, Labels: []string{bond.Bond, bond.Mode}})
+		}
+		for _, member := range bond.Members {
+			if v, ok := member.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{bond.Bond, member.Member}})
+			}
+			if spec.source == "hash load" {
+				for _, hash := range member.Hashes {
+					samples = append(samples, Sample{Value: hash.Load, Labels: []string{bond.Bond, member.Member, hash.Hash}})
+				}
+			}
+		}
+	}
+	return samples
+}
+
+// lacpSamples returns the values of a lacp/show registry metric.
+func (metrics *OvsMetric) lacpSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, bond := range metrics.LACPs {
+		if v, ok := bond.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{bond.Bond}})
+		}
+		for _, member := range bond.Members {
+			if v, ok := member.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{bond.Bond, member.Member}})
+			}
+			if spec.source == "partner" && member.PartnerSysID != "" {
+				samples = append(samples, Sample{Value: 1, Labels: []string{bond.Bond, member.Member, member.PartnerSysID, member.PartnerPortID, member.PartnerKey}})
+			}
+		}
+	}
+	return samples
+}
+
+// splitDashedSections splits output into the names of its "---- <name> ----"
+// headers, e.g. bonds or interfaces, and the blocks following them.
+func splitDashedSections(output string) (names []string, bodies []string) {
+	headerRegexp := regexp.MustCompile(`(?m)^---- (\S+) ----[ \t]*$`)
+	headers := headerRegexp.FindAllStringSubmatchIndex(output, -1)
+	for i, header := range headers {
+		end := len(output)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		names = append(names, output[header[2]:header[3]])
+		bodies = append(bodies, output[header[1]:end])
+	}
+	return names, bodies
+}
+
+func parseBonds(metrics *OvsMetric, bondShow string) {
+	modeRegexp := regexp.MustCompile(`(?m)^bond_mode:\s*(\S+)`)
+	// e.g. "member dpdk0: enabled", "slave dpdk0: disabled" before OVS 2.15
+	memberRegexp := regexp.MustCompile(`^(?:member|slave) (\S+): (enabled|disabled)`)
+	mayEnableRegexp := regexp.MustCompile(`^[ \t]+may_enable: (true|false)`)
+	activeRegexp := regexp.MustCompile(`^[ \t]+active (?:member|slave)[ \t]*$`)
+	// e.g. "  hash 15: 3 kB load"
+	hashRegexp := regexp.MustCompile(`^[ \t]+hash (\d+): (\d+) kB load`)
+
+	metrics.Bonds = nil
+	bonds, bodies := splitDashedSections(bondShow)
+	for i, body := range bodies {
+		bond := BondMetric{Bond: bonds[i]}
+		if match := modeRegexp.FindStringSubmatch(body); match != nil {
+			bond.Mode = match[1]
+		}
+
+		for _, line := range strings.Split(body, "\n") {
+			if match := memberRegexp.FindStringSubmatch(line); match != nil {
+				bond.Members = append(bond.Members, BondMemberMetric{
+					Member: match[1],
+					Values: map[string]float64{"enabled": boolValue(match[2] == "enabled"), "active": 0},
+				})
+				continue
+			}
+			if len(bond.Members) == 0 {
+				continue
+			}
+			member := &bond.Members[len(bond.Members)-1]
+			if match := mayEnableRegexp.FindStringSubmatch(line); match != nil {
+				member.Values["may_enable"] = boolValue(match[1] == "true")
+			} else if activeRegexp.MatchString(line) {
+				member.Values["active"] = 1
+			} else if match := hashRegexp.FindStringSubmatch(line); match != nil {
+				load, err := strconv.ParseFloat(match[2], 64)
+				if err != nil {
+					metrics.parseFailure(bondCommand, "hash load")
+					continue
+				}
+				member.Hashes = append(member.Hashes, BondHashMetric{Hash: match[1], Load: load * 1024})
+			}
+		}
+
+		metrics.Bonds = append(metrics.Bonds, bond)
+	}
+}
+
+func parseLACP(metrics *OvsMetric, lacpShow string) {
+	statusRegexp := regexp.MustCompile(`(?m)^[ \t]+status: (.*)$`)
+	// e.g. "member: dpdk0: current attached", "slave: ..." before OVS 2.15
+	memberRegexp := regexp.MustCompile(`^(?:member|slave): (\S+): (.*)$`)
+	partnerRegexp := regexp.MustCompile(`^[ \t]+partner (sys_id|port_id|key): (\S+)`)
+
+	metrics.LACPs = nil
+	bonds, bodies := splitDashedSections(lacpShow)
+	for i, body := range bodies {
+		bond := LACPMetric{Bond: bonds[i], Values: make(map[string]float64)}
+		if match := statusRegexp.FindStringSubmatch(body); match != nil {
+			bond.Values["negotiated"] = boolValue(strings.Contains(match[1], "negotiated"))
+		}
+
+		for _, line := range strings.Split(body, "\n") {
+			if match := memberRegexp.FindStringSubmatch(line); match != nil {
+				status := strings.Fields(match[2])
+				bond.Members = append(bond.Members, LACPMemberMetric{
+					Member: match[1],
+					Values: map[string]float64{
+						"current":  boolValue(slices.Contains(status, "current")),
+						"attached": boolValue(slices.Contains(status, "attached")),
+					},
+				})
+				continue
+			}
+			if len(bond.Members) == 0 {
+				continue
+			}
+			member := &bond.Members[len(bond.Members)-1]
+			if match := partnerRegexp.FindStringSubmatch(line); match != nil {
+				switch match[1] {
+				case "sys_id":
+					member.PartnerSysID = match[2]
+				case "port_id":
+					member.PartnerPortID = match[2]
+				case "key":
+					member.PartnerKey = match[2]
+				}
+			}
+		}
+
+		metrics.LACPs = append(metrics.LACPs, bond)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseBonds(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `---- bond0 ----
+bond_mode: balance-tcp
+bond may use recirculation: yes, Recirc-ID : 1
+bond-hash-basis: 0
+lb_output action: disabled, bond-id: -1
+updelay: 0 ms
+downdelay: 0 ms
+next rebalance: 6192 ms
+lacp_status: negotiated
+lacp_fallback_ab: false
+active-backup primary: <none>
+active member mac: 0c:42:a1:ef:9c:1e(dpdk0)
+
+member dpdk0: enabled
+  active member
+  may_enable: true
+  hash 15: 3 kB load
+  hash 87: 1 kB load
+
+member dpdk1: disabled
+  may_enable: false
+`,
+			metric: OvsMetric{
+				Bonds: []BondMetric{
+					{
+						Bond: "bond0",
+						Mode: "balance-tcp",
+						Members: []BondMemberMetric{
+							{
+								Member: "dpdk0",
+								Values: map[string]float64{"enabled": 1, "may_enable": 1, "active": 1},
+								Hashes: []BondHashMetric{{Hash: "15", Load: 3072}, {Hash: "87", Load: 1024}},
+							},
+							{
+								Member: "dpdk1",
+								Values: map[string]float64{"enabled": 0, "may_enable": 0, "active": 0},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "slaves",
+			output: `---- bond1 ----
+bond_mode: active-backup
+updelay: 0 ms
+downdelay: 0 ms
+lacp_status: off
+active slave mac: 0c:42:a1:ef:9c:1f(eth1)
+
+slave eth1: enabled
+	active slave
+	may_enable: true
+
+slave eth2: enabled
+	may_enable: true
+`,
+			metric: OvsMetric{
+				Bonds: []BondMetric{
+					{
+						Bond: "bond1",
+						Mode: "active-backup",
+						Members: []BondMemberMetric{
+							{Member: "eth1", Values: map[string]float64{"enabled": 1, "may_enable": 1, "active": 1}},
+							{Member: "eth2", Values: map[string]float64{"enabled": 1, "may_enable": 1, "active": 0}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseBonds(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseLACP(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `---- bond0 ----
+  status: active negotiated
+  sys_id: 0c:42:a1:ef:9c:1e
+  sys_priority: 65534
+  aggregation key: 1
+  lacp_time: slow
+
+member: dpdk0: current attached
+  port_id: 1
+  port_priority: 65535
+  may_enable: true
+
+  actor sys_id: 0c:42:a1:ef:9c:1e
+  actor sys_priority: 65534
+  actor port_id: 1
+  actor port_priority: 65535
+  actor key: 1
+  actor state: activity aggregation synchronized collecting distributing
+
+  partner sys_id: 00:23:04:ee:be:64
+  partner sys_priority: 32667
+  partner port_id: 16
+  partner port_priority: 32768
+  partner key: 13
+  partner state: activity aggregation synchronized collecting distributing
+
+member: dpdk1: defaulted detached
+  port_id: 2
+  port_priority: 65535
+  may_enable: false
+
+  partner sys_id: 00:00:00:00:00:00
+  partner sys_priority: 0
+  partner port_id: 0
+  partner port_priority: 0
+  partner key: 0
+  partner state:
+`,
+			metric: OvsMetric{
+				LACPs: []LACPMetric{
+					{
+						Bond:   "bond0",
+						Values: map[string]float64{"negotiated": 1},
+						Members: []LACPMemberMetric{
+							{
+								Member:        "dpdk0",
+								PartnerSysID:  "00:23:04:ee:be:64",
+								PartnerPortID: "16",
+								PartnerKey:    "13",
+								Values:        map[string]float64{"current": 1, "attached": 1},
+							},
+							{
+								Member:        "dpdk1",
+								PartnerSysID:  "00:00:00:00:00:00",
+								PartnerPortID: "0",
+								PartnerKey:    "0",
+								Values:        map[string]float64{"current": 0, "attached": 0},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseLACP(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConntrackMetric holds the userspace conntrack statistics from
+// dpctl/ct-stats-show, ct-get-nconns and ct-get-maxconns.
+type ConntrackMetric struct {
+	// Values hold "connections" and "max connections" when their command
+	// succeeded.
+	Values map[string]float64
+	// Protocols counts the connections by protocol and TCPStates the TCP
+	// connections by state, as printed by ct-stats-show -m.
+	Protocols []ConntrackCount
+	TCPStates []ConntrackCount
+}
+
+// ConntrackCount is a number of connections in a ct-stats-show line.
+type ConntrackCount struct {
+	Name        string
+	Connections float64
+}
+
+// conntrackSamples returns the values of a conntrack registry metric.
+func (metrics *OvsMetric) conntrackSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	switch spec.source {
+	case "protocol":
+		for _, count := range metrics.Conntrack.Protocols {
+			samples = append(samples, Sample{Value: count.Connections, Labels: []string{count.Name}})
+		}
+	case "tcp state":
+		for _, count := range metrics.Conntrack.TCPStates {
+			samples = append(samples, Sample{Value: count.Connections, Labels: []string{count.Name}})
+		}
+	case "utilization":
+		connections, ok := metrics.Conntrack.Values["connections"]
+		maxConnections, maxOk := metrics.Conntrack.Values["max connections"]
+		if ok && maxOk && maxConnections > 0 {
+			samples = append(samples, Sample{Value: connections / maxConnections})
+		}
+	default:
+		if v, ok := metrics.Conntrack.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v})
+		}
+	}
+	return samples
+}
+
+// conntrackZoneSamples returns the values of a ct-get-limits registry metric,
+// for at most maxZones zones besides the default one.
+func (metrics *OvsMetric) conntrackZoneSamples(spec metricSpec, maxZones int) []Sample {
+	if spec.source == "zones" {
+		if !metrics.succeeded(ctLimitsCommand) {
+			return nil
+		}
+		zones := 0
+		for _, zone := range metrics.ConntrackZones {
+			if zone.Zone != "default" {
+				zones++
+			}
+		}
+		return []Sample{{Value: float64(zones)}}
+	}
+
+	var samples []Sample
+	for _, zone := range metrics.busiestConntrackZones(maxZones) {
+		if v, ok := zone.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{zone.Zone}})
+		}
+	}
+	return samples
+}
+
+func parseConntrackStats(metrics *OvsMetric, ctStats string) {
+	// e.g. "	TCP: 2", the total being printed as "Total: 3"
+	protocolRegexp := regexp.MustCompile(`(?m)^[ \t]*(\w+):[ \t]*(\d+)[ \t]*$`)
+	// e.g. "	  [ESTABLISHED]=1"
+	tcpStateRegexp := regexp.MustCompile(`(?m)^[ \t]*\[(\w+)\]=(\d+)`)
+
+	metrics.Conntrack.Protocols = nil
+	for _, match := range protocolRegexp.FindAllStringSubmatch(ctStats, -1) {
+		if match[1] == "Total" {
+			continue
+		}
+		v, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			metrics.parseFailure(ctStatsCommand, "protocol")
+			continue
+		}
+		metrics.Conntrack.Protocols = append(metrics.Conntrack.Protocols, ConntrackCount{Name: match[1], Connections: v})
+	}
+
+	metrics.Conntrack.TCPStates = nil
+	for _, match := range tcpStateRegexp.FindAllStringSubmatch(ctStats, -1) {
+		v, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			metrics.parseFailure(ctStatsCommand, "tcp state")
+			continue
+		}
+		metrics.Conntrack.TCPStates = append(metrics.Conntrack.TCPStates, ConntrackCount{Name: match[1], Connections: v})
+	}
+}
+
+// parseConntrackValue parses the single number printed by ct-get-nconns or
+// ct-get-maxconns into the conntrack value named source.
+func parseConntrackValue(metrics *OvsMetric, command string, source string, output string) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+	if err != nil {
+		metrics.parseFailure(command, source)
+		return
+	}
+	if metrics.Conntrack.Values == nil {
+		metrics.Conntrack.Values = make(map[string]float64)
+	}
+	metrics.Conntrack.Values[source] = v
+}
+
+// ConntrackZone is a zone limit printed by ct-get-limits. Zone is "default"
+// for the default limit, which has no count.
+type ConntrackZone struct {
+	Zone string
+	// Values hold "limit" and "count" when printed.
+	Values map[string]float64
+}
+
+func parseConntrackLimits(metrics *OvsMetric, ctLimits string) {
+	defaultRegexp := regexp.MustCompile(`(?m)^[ \t]*default limit=(\d+)`)
+	// e.g. "zone=1,limit=10,count=3"
+	zoneRegexp := regexp.MustCompile(`(?m)^[ \t]*zone=(\d+),limit=(\d+),count=(\d+)`)
+
+	metrics.ConntrackZones = nil
+	if match := defaultRegexp.FindStringSubmatch(ctLimits); match != nil {
+		v, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			metrics.parseFailure(ctLimitsCommand, "limit")
+		} else {
+			metrics.ConntrackZones = append(metrics.ConntrackZones, ConntrackZone{Zone: "default", Values: map[string]float64{"limit": v}})
+		}
+	}
+	for _, match := range zoneRegexp.FindAllStringSubmatch(ctLimits, -1) {
+		limit, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			metrics.parseFailure(ctLimitsCommand, "limit")
+			continue
+		}
+		count, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			metrics.parseFailure(ctLimitsCommand, "count")
+			continue
+		}
+		metrics.ConntrackZones = append(metrics.ConntrackZones, ConntrackZone{Zone: match[1], Values: map[string]float64{"limit": limit, "count": count}})
+	}
+}
+
+// busiestConntrackZones returns the default limit and at most max zones,
+// those closest to their limit first, or every zone if max is 0.
+func (metrics *OvsMetric) busiestConntrackZones(max int) []ConntrackZone {
+	if max <= 0 || len(metrics.ConntrackZones) <= max {
+		return metrics.ConntrackZones
+	}
+
+	var defaultZone []ConntrackZone
+	var zones []ConntrackZone
+	for _, zone := range metrics.ConntrackZones {
+		if zone.Zone == "default" {
+			defaultZone = append(defaultZone, zone)
+		} else {
+			zones = append(zones, zone)
+		}
+	}
+	utilization := func(zone ConntrackZone) float64 {
+		if zone.Values["limit"] == 0 {
+			return 0
+		}
+		return zone.Values["count"] / zone.Values["limit"]
+	}
+	sort.SliceStable(zones, func(i, j int) bool {
+		return utilization(zones[i]) > utilization(zones[j])
+	})
+	if len(zones) > max {
+		zones = zones[:max]
+	}
+	return append(defaultZone, zones...)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseConntrackStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `Connections Stats:
+    Total: 6
+	ICMP: 1
+	TCP: 4
+	  [ESTABLISHED]=3
+	  [TIME_WAIT]=1
+	UDP: 1
+`,
+			metric: OvsMetric{
+				Conntrack: ConntrackMetric{
+					Protocols: []ConntrackCount{{Name: "ICMP", Connections: 1}, {Name: "TCP", Connections: 4}, {Name: "UDP", Connections: 1}},
+					TCPStates: []ConntrackCount{{Name: "ESTABLISHED", Connections: 3}, {Name: "TIME_WAIT", Connections: 1}},
+				},
+			},
+		},
+		{
+			name: "no connections",
+			output: `Connections Stats:
+    Total: 0
+`,
+			metric: OvsMetric{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseConntrackStats(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_conntrackUtilization(t *testing.T) {
+	tests := []struct {
+		name     string
+		nconns   string
+		maxconns string
+		samples  []Sample
+	}{
+		{
+			name:     "output1",
+			nconns:   "1500\n",
+			maxconns: "3000000\n",
+			samples:  []Sample{{Value: 0.0005}},
+		},
+		{
+			name:     "unparsable maxconns",
+			nconns:   "1500\n",
+			maxconns: "ovs-vswitchd: datapath not found\n",
+		},
+		{
+			name:     "no limit",
+			nconns:   "0\n",
+			maxconns: "0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseConntrackValue(&ovsMetric, ctNConnsCommand, "connections", tt.nconns)
+			parseConntrackValue(&ovsMetric, ctMaxConnsCommand, "max connections", tt.maxconns)
+
+			samples := ovsMetric.conntrackSamples(metricSpec{command: ctNConnsCommand, source: "utilization"})
+			diff := cmp.Diff(samples, tt.samples)
+			if diff != "" {
+				t.Errorf("Samples are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseConntrackLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `default limit=0
+zone=1,limit=10,count=3
+zone=2,limit=20,count=18`,
+			metric: OvsMetric{
+				ConntrackZones: []ConntrackZone{
+					{Zone: "default", Values: map[string]float64{"limit": 0}},
+					{Zone: "1", Values: map[string]float64{"limit": 10, "count": 3}},
+					{Zone: "2", Values: map[string]float64{"limit": 20, "count": 18}},
+				},
+			},
+		},
+		{
+			name:   "default only",
+			output: "default limit=1000\n",
+			metric: OvsMetric{
+				ConntrackZones: []ConntrackZone{
+					{Zone: "default", Values: map[string]float64{"limit": 1000}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseConntrackLimits(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_busiestConntrackZones(t *testing.T) {
+	var ovsMetric OvsMetric
+	parseConntrackLimits(&ovsMetric, `default limit=0
+zone=1,limit=10,count=3
+zone=2,limit=0,count=500
+zone=3,limit=20,count=18
+zone=4,limit=10,count=9`)
+
+	tests := []struct {
+		name  string
+		max   int
+		zones []string
+	}{
+		{name: "uncapped", max: 0, zones: []string{"default", "1", "2", "3", "4"}},
+		{name: "capped", max: 2, zones: []string{"default", "3", "4"}},
+		{name: "above zone count", max: 10, zones: []string{"default", "1", "2", "3", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var zones []string
+			for _, zone := range ovsMetric.busiestConntrackZones(tt.max) {
+				zones = append(zones, zone.Zone)
+			}
+
+			diff := cmp.Diff(zones, tt.zones)
+			if diff != "" {
+				t.Errorf("Zones are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_conntrackZoneSamples(t *testing.T) {
+	ovsMetric := OvsMetric{Commands: []CommandResult{{Command: ctLimitsCommand}}}
+	parseConntrackLimits(&ovsMetric, `default limit=0
+zone=1,limit=10,count=3
+zone=3,limit=20,count=18`)
+	options := sampleOptions{ctMaxZones: 1}
+
+	samples := make(map[string][]Sample)
+	for _, spec := range metricRegistry {
+		if spec.command == ctLimitsCommand {
+			samples[spec.name] = ovsMetric.samples(spec, options)
+		}
+	}
+
+	expected := map[string][]Sample{
+		"ovsdp_ct_zone_limit": {{Value: 0, Labels: []string{"default"}}, {Value: 20, Labels: []string{"3"}}},
+		"ovsdp_ct_zone_count": {{Value: 18, Labels: []string{"3"}}},
+		"ovsdp_ct_zones":      {{Value: 2}},
+	}
+	diff := cmp.Diff(samples, expected)
+	if diff != "" {
+		t.Errorf("Samples are different:\n%s", diff)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DatapathMetric holds the stats of a datapath from dpctl/show.
+type DatapathMetric struct {
+	Datapath string
+	// Values are keyed by the name of each stats line followed by the name
+	// of the value, e.g. "lookups hit" or "masks hit/pkt", or only the
+	// line name for single value lines like "flows".
+	Values map[string]float64
+}
+
+// BridgeMetric holds the ports of a bridge from dpif/show.
+type BridgeMetric struct {
+	Datapath string
+	Bridge   string
+	Ports    []BridgePort
+}
+
+// BridgePort is a bridge port with its OpenFlow port number.
+type BridgePort struct {
+	Port         string
+	OpenFlowPort string
+	// Values hold "datapath port", the port number in the datapath, unless
+	// the port isn't in the datapath.
+	Values map[string]float64
+}
+
+// datapathSamples returns the values of a dpctl/show registry metric.
+func (metrics *OvsMetric) datapathSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, datapath := range metrics.Datapaths {
+		if v, ok := datapath.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{datapath.Datapath}})
+		}
+	}
+	return samples
+}
+
+// bridgeSamples returns the values of a dpif/show registry metric.
+func (metrics *OvsMetric) bridgeSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, bridge := range metrics.Bridges {
+		for _, port := range bridge.Ports {
+			if v, ok := port.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{bridge.Datapath, bridge.Bridge, port.Port}})
+			}
+		}
+	}
+	return samples
+}
+
+// splitDatapathSections splits output into the names of its unindented
+// "<type>@<name>:" datapath headers and the blocks following them.
+func splitDatapathSections(output string) (datapaths []string, bodies []string) {
+	headerRegexp := regexp.MustCompile(`(?m)^(\S+@\S+):.*$`)
+	headers := headerRegexp.FindAllStringSubmatchIndex(output, -1)
+	for i, header := range headers {
+		end := len(output)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		datapaths = append(datapaths, output[header[2]:header[3]])
+		bodies = append(bodies, output[header[1]:end])
+	}
+	return datapaths, bodies
+}
+
+func parseDatapaths(metrics *OvsMetric, dpctlShow string) {
+	// e.g. "  masks: hit:3000 total:5 hit/pkt:2.32"
+	lineRegexp := regexp.MustCompile(`(?m)^  (lookups|masks|cache):(.*)$`)
+	valueRegexp := regexp.MustCompile(`([\w/-]+):(\d+(?:\.\d+)?)`)
+	flowsRegexp := regexp.MustCompile(`(?m)^  flows:\s*(\d+)`)
+
+	metrics.Datapaths = nil
+	datapaths, bodies := splitDatapathSections(dpctlShow)
+	for i, body := range bodies {
+		datapath := DatapathMetric{Datapath: datapaths[i], Values: make(map[string]float64)}
+
+		for _, line := range lineRegexp.FindAllStringSubmatch(body, -1) {
+			for _, match := range valueRegexp.FindAllStringSubmatch(line[2], -1) {
+				source := line[1] + " " + match[1]
+				v, err := strconv.ParseFloat(match[2], 64)
+				if err != nil {
+					metrics.parseFailure(dpctlShowCommand, source)
+					continue
+				}
+				datapath.Values[source] = v
+			}
+		}
+		if match := flowsRegexp.FindStringSubmatch(body); match != nil {
+			v, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				metrics.parseFailure(dpctlShowCommand, "flows")
+			} else {
+				datapath.Values["flows"] = v
+			}
+		}
+
+		metrics.Datapaths = append(metrics.Datapaths, datapath)
+	}
+}
+
+func parseBridges(metrics *OvsMetric, dpifShow string) {
+	bridgeRegexp := regexp.MustCompile(`^  (\S+):$`)
+	// e.g. "    dpdk0 1/2: (dpdk: configured_rx_queues=1)", the datapath port
+	// being "none" for ports missing from the datapath.
+	portRegexp := regexp.MustCompile(`^    (\S+) (\d+)/(\d+|none)`)
+
+	metrics.Bridges = nil
+	datapaths, bodies := splitDatapathSections(dpifShow)
+	for i, body := range bodies {
+		var bridges []BridgeMetric
+		for _, line := range strings.Split(body, "\n") {
+			if match := bridgeRegexp.FindStringSubmatch(line); match != nil {
+				bridges = append(bridges, BridgeMetric{Datapath: datapaths[i], Bridge: match[1]})
+				continue
+			}
+			match := portRegexp.FindStringSubmatch(line)
+			if match == nil || len(bridges) == 0 {
+				continue
+			}
+			port := BridgePort{Port: match[1], OpenFlowPort: match[2], Values: make(map[string]float64)}
+			if match[3] != "none" {
+				v, err := strconv.ParseFloat(match[3], 64)
+				if err != nil {
+					metrics.parseFailure(dpifShowCommand, "datapath port")
+				} else {
+					port.Values["datapath port"] = v
+				}
+			}
+			bridges[len(bridges)-1].Ports = append(bridges[len(bridges)-1].Ports, port)
+		}
+		metrics.Bridges = append(metrics.Bridges, bridges...)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseDatapaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `netdev@ovs-netdev:
+  lookups: hit:1234 missed:56 lost:0
+  flows: 10
+  port 0: ovs-netdev (tap)
+  port 1: br0 (tap)
+  port 2: dpdk0 (dpdk: configured_rx_queues=1, configured_tx_queues=2)
+system@ovs-system:
+  lookups: hit:98765 missed:321 lost:2
+  flows: 7
+  masks: hit:300000 total:5 hit/pkt:3.04
+  cache: hit:90000 hit-rate:91.12%
+  caches:
+    masks-cache: size:256
+  port 0: ovs-system (internal)
+  port 1: br-ex (internal)
+`,
+			metric: OvsMetric{
+				Datapaths: []DatapathMetric{
+					{
+						Datapath: "netdev@ovs-netdev",
+						Values:   map[string]float64{"lookups hit": 1234, "lookups missed": 56, "lookups lost": 0, "flows": 10},
+					},
+					{
+						Datapath: "system@ovs-system",
+						Values: map[string]float64{
+							"lookups hit": 98765, "lookups missed": 321, "lookups lost": 2, "flows": 7,
+							"masks hit": 300000, "masks total": 5, "masks hit/pkt": 3.04,
+							"cache hit": 90000, "cache hit-rate": 91.12,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseDatapaths(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseBridges(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `netdev@ovs-netdev: hit:1234 missed:56
+  br-int:
+    br-int 65534/1: (tap)
+    dpdk0 1/2: (dpdk: configured_rx_queues=1, configured_tx_queues=2)
+    vhu7f3a2c1e-4b 2/none: (dpdkvhostuserclient: configured_rx_queues=1)
+  br-tun:
+    br-tun 65534/3: (tap)
+system@ovs-system: hit:0 missed:0
+  br-ex:
+    br-ex 65534/1: (internal)
+`,
+			metric: OvsMetric{
+				Bridges: []BridgeMetric{
+					{
+						Datapath: "netdev@ovs-netdev",
+						Bridge:   "br-int",
+						Ports: []BridgePort{
+							{Port: "br-int", OpenFlowPort: "65534", Values: map[string]float64{"datapath port": 1}},
+							{Port: "dpdk0", OpenFlowPort: "1", Values: map[string]float64{"datapath port": 2}},
+							{Port: "vhu7f3a2c1e-4b", OpenFlowPort: "2", Values: map[string]float64{}},
+						},
+					},
+					{
+						Datapath: "netdev@ovs-netdev",
+						Bridge:   "br-tun",
+						Ports: []BridgePort{
+							{Port: "br-tun", OpenFlowPort: "65534", Values: map[string]float64{"datapath port": 3}},
+						},
+					},
+					{
+						Datapath: "system@ovs-system",
+						Bridge:   "br-ex",
+						Ports: []BridgePort{
+							{Port: "br-ex", OpenFlowPort: "65534", Values: map[string]float64{"datapath port": 1}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseBridges(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
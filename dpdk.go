@@ -0,0 +1,121 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// DPDKMempoolMetric holds a DPDK mempool from netdev-dpdk/get-mempool-info.
+type DPDKMempoolMetric struct {
+	Name   string
+	Socket string
+	// Values hold "populated_size", "available" objects in the common pool
+	// and caches, and objects "in use".
+	Values map[string]float64
+}
+
+// DPDKHeapMetric holds a malloc heap from dpdk/get-malloc-stats. Heap is the
+// socket before DPDK 19.05, and the heap id since, which differs from the
+// socket for external heaps.
+type DPDKHeapMetric struct {
+	Heap string
+	// Values are keyed by the name before the colon, e.g. "Heap_size" or
+	// "Alloc_count".
+	Values map[string]float64
+}
+
+// dpdkUnsupported is the error of the DPDK commands, only registered by
+// ovs-vswitchd once DPDK is initialized, when it isn't.
+var dpdkUnsupported = []string{"is not a valid command"}
+
+// dpdkSamples returns the values of a DPDK registry metric.
+func (metrics *OvsMetric) dpdkSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	switch spec.command {
+	case dpdkMempoolCommand:
+		for _, mempool := range metrics.DPDKMempools {
+			if v, ok := mempool.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{mempool.Name, mempool.Socket}})
+			}
+		}
+	case dpdkMallocCommand:
+		for _, heap := range metrics.DPDKHeaps {
+			if v, ok := heap.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{heap.Heap}})
+			}
+		}
+	}
+	return samples
+}
+
+func parseDPDKMempools(metrics *OvsMetric, mempoolInfo string) {
+	headerRegexp := regexp.MustCompile(`(?m)^mempool <([^>]*)>`)
+	// e.g. "  populated_size=262144"
+	valueRegexp := regexp.MustCompile(`(?m)^[ \t]*(\w+)=(\d+)[ \t]*$`)
+
+	metrics.DPDKMempools = nil
+	headers := headerRegexp.FindAllStringSubmatchIndex(mempoolInfo, -1)
+	for i, header := range headers {
+		end := len(mempoolInfo)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		mempool := DPDKMempoolMetric{Name: mempoolInfo[header[2]:header[3]], Values: make(map[string]float64)}
+
+		values := make(map[string]float64)
+		for _, match := range valueRegexp.FindAllStringSubmatch(mempoolInfo[header[1]:end], -1) {
+			v, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				metrics.parseFailure(dpdkMempoolCommand, match[1])
+				continue
+			}
+			values[match[1]] = v
+			if match[1] == "socket_id" {
+				mempool.Socket = match[2]
+			}
+		}
+
+		size, sizeOk := values["populated_size"]
+		common, commonOk := values["common_pool_count"]
+		if sizeOk {
+			mempool.Values["populated_size"] = size
+		}
+		if commonOk {
+			available := common + values["total_cache_count"]
+			mempool.Values["available"] = available
+			if sizeOk {
+				mempool.Values["in use"] = size - available
+			}
+		}
+
+		metrics.DPDKMempools = append(metrics.DPDKMempools, mempool)
+	}
+}
+
+func parseDPDKMalloc(metrics *OvsMetric, mallocStats string) {
+	// "Socket:0" before DPDK 19.05, "Heap id:0" since.
+	headerRegexp := regexp.MustCompile(`(?m)^(?:Socket|Heap id):\s*(\d+)`)
+	// e.g. "	Heap_size:1073741824,"
+	valueRegexp := regexp.MustCompile(`(?m)^[ \t]*(\w+):\s*(\d+),?[ \t]*$`)
+
+	metrics.DPDKHeaps = nil
+	headers := headerRegexp.FindAllStringSubmatchIndex(mallocStats, -1)
+	for i, header := range headers {
+		end := len(mallocStats)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		heap := DPDKHeapMetric{Heap: mallocStats[header[2]:header[3]], Values: make(map[string]float64)}
+
+		for _, match := range valueRegexp.FindAllStringSubmatch(mallocStats[header[1]:end], -1) {
+			v, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				metrics.parseFailure(dpdkMallocCommand, match[1])
+				continue
+			}
+			heap.Values[match[1]] = v
+		}
+
+		metrics.DPDKHeaps = append(metrics.DPDKHeaps, heap)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseDPDKMempools(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `mempool <ovs4e6d1ab200021580262144>@0x17f9e0f00
+  flags=10
+  socket_id=0
+  pool=0x17f8e0880
+  iova=0x17f9e0f00
+  nb_mem_chunks=1
+  size=262144
+  populated_size=262144
+  header_size=64
+  elt_size=2880
+  trailer_size=0
+  total_obj_size=2944
+  private_data_size=64
+  ops_index=0
+  ops_name: <ring_mp_mc>
+  avg bytes/object=2944.011719
+  internal cache infos:
+    cache_size=512
+    cache_count[0]=300
+    cache_count[1]=212
+    total_cache_count=512
+  common_pool_count=250000
+  no statistics available
+mempool <ovs5f7e2bc30002158065536>@0x27f9e0f00
+  flags=10
+  socket_id=1
+  size=65536
+  populated_size=65536
+  internal cache infos:
+    cache_size=0
+    total_cache_count=0
+  common_pool_count=65536
+`,
+			metric: OvsMetric{
+				DPDKMempools: []DPDKMempoolMetric{
+					{
+						Name:   "ovs4e6d1ab200021580262144",
+						Socket: "0",
+						Values: map[string]float64{"populated_size": 262144, "available": 250512, "in use": 11632},
+					},
+					{
+						Name:   "ovs5f7e2bc30002158065536",
+						Socket: "1",
+						Values: map[string]float64{"populated_size": 65536, "available": 65536, "in use": 0},
+					},
+				},
+			},
+		},
+		{
+			name:   "out of range",
+			output: "mempool <ovs5f7e2bc30002158065536>@0x27f9e0f00\n  socket_id=0\n  populated_size=1" + strings.Repeat("0", 400) + "\n  total_cache_count=0\n  common_pool_count=10\n",
+			metric: OvsMetric{
+				DPDKMempools: []DPDKMempoolMetric{
+					{
+						Name:   "ovs5f7e2bc30002158065536",
+						Socket: "0",
+						Values: map[string]float64{"available": 10},
+					},
+				},
+				ParseFailures: []string{"ovsdp_dpdk_mempool_size"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseDPDKMempools(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseDPDKMalloc(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "sockets",
+			output: `Socket:0
+	Heap_size:1073741824,
+	Free_size:1022478464,
+	Alloc_size:51263360,
+	Greatest_free_size:1022478336,
+	Alloc_count:173,
+	Free_count:1,
+`,
+			metric: OvsMetric{
+				DPDKHeaps: []DPDKHeapMetric{
+					{
+						Heap: "0",
+						Values: map[string]float64{
+							"Heap_size": 1073741824, "Free_size": 1022478464, "Alloc_size": 51263360,
+							"Greatest_free_size": 1022478336, "Alloc_count": 173, "Free_count": 1,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "heaps",
+			output: `Heap id:0
+	Heap name:socket_0
+	Heap_size:1073741824,
+	Free_size:1022478464,
+	Alloc_size:51263360,
+	Greatest_free_size:1022478336,
+	Alloc_count:173,
+	Free_count:1,
+Heap id:1
+	Heap name:socket_1
+	Heap_size:0,
+	Free_size:0,
+	Alloc_size:0,
+	Greatest_free_size:0,
+	Alloc_count:0,
+	Free_count:0,
+`,
+			metric: OvsMetric{
+				DPDKHeaps: []DPDKHeapMetric{
+					{
+						Heap: "0",
+						Values: map[string]float64{
+							"Heap_size": 1073741824, "Free_size": 1022478464, "Alloc_size": 51263360,
+							"Greatest_free_size": 1022478336, "Alloc_count": 173, "Free_count": 1,
+						},
+					},
+					{
+						Heap: "1",
+						Values: map[string]float64{
+							"Heap_size": 0, "Free_size": 0, "Alloc_size": 0,
+							"Greatest_free_size": 0, "Alloc_count": 0, "Free_count": 0,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseDPDKMalloc(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+// unknownCommandAppctl fails every command like ovs-vswitchd built without
+// DPDK fails the DPDK commands.
+type unknownCommandAppctl struct{}
+
+func (unknownCommandAppctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	return "\"" + command + "\" is not a valid command\novs-appctl: ovs-vswitchd: server returned an error\n", errors.New("exit status 2")
+}
+
+func Test_runOptionalCommandDPDK(t *testing.T) {
+	var ovsMetric OvsMetric
+	if _, ok := ovsMetric.runOptionalCommand(context.Background(), unknownCommandAppctl{}, dpdkUnsupported, dpdkMallocCommand); ok {
+		t.Error("Expected the command to fail")
+	}
+	if len(ovsMetric.Commands) != 0 {
+		t.Errorf("Expected the unknown command to be skipped, got %v", ovsMetric.Commands)
+	}
+
+	if _, ok := ovsMetric.runOptionalCommand(context.Background(), fakeAppctl{}, dpdkUnsupported, dpdkMallocCommand); ok {
+		t.Error("Expected the command to fail")
+	}
+	if len(ovsMetric.Commands) != 1 || ovsMetric.Commands[0].Err == nil {
+		t.Errorf("Expected the failed command to be recorded, got %v", ovsMetric.Commands)
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func Test_ovsDPCollectorPolling(t *testing.T) {
+	ctl := fakeAppctl{
+		"coverage/show": "datapath_drop_meter   0.0/sec     0.000/sec        0.0000/sec   total: 8\n",
+	}
+	collector := newOvsDPCollector(ctl, nil, nil, time.Second, coverageFilter{}, 0, nil, false)
+	collector.polling = true
+	collector.maxStaleness = time.Minute
+
+	tests := []struct {
+		name     string
+		age      time.Duration
+		expected string
+	}{
+		{
+			name: "fresh",
+			expected: `
+# HELP ovsdp_datapath_drop_meter Drop packet in the OpenFlow (1.3+) Meter Table
+# TYPE ovsdp_datapath_drop_meter counter
+ovsdp_datapath_drop_meter 8
+# HELP ovsdp_up Whether OVS answered at least one command during the scrape
+# TYPE ovsdp_up gauge
+ovsdp_up 1
+`,
+		},
+		{
+			name: "stale",
+			age:  2 * time.Minute,
+			expected: `
+# HELP ovsdp_up Whether OVS answered at least one command during the scrape
+# TYPE ovsdp_up gauge
+ovsdp_up 0
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector.poll(context.Background())
+			collector.snapshotTime = collector.snapshotTime.Add(-tt.age)
+
+			err := testutil.CollectAndCompare(collector, strings.NewReader(tt.expected), "ovsdp_datapath_drop_meter", "ovsdp_up")
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// fakeOvsdb answers selects with the JSON rows of tables.
+type fakeOvsdb map[string]string
+
+func (db fakeOvsdb) selectRows(ctx context.Context, table string, columns ...string) ([]ovsdbRow, error) {
+	var rows []ovsdbRow
+	err := json.Unmarshal([]byte(db[table]), &rows)
+	return rows, err
+}
+
+func Test_ovsDPCollectorExternalIDs(t *testing.T) {
+	db := fakeOvsdb{
+		"Interface": `[
+  {"name": "dpdk0", "type": "dpdk", "mtu": 9000, "external_ids": ["map", []]},
+  {"name": "tap1", "type": "", "mtu": 1500, "external_ids": ["map", [["iface-id", "pod1_ns1"], ["k8s.ovn.org/pod", "ns1/pod1"], ["attached-mac", "0a:58:0a:80:00:05"]]]}
+]`,
+	}
+	collector := newOvsDPCollector(fakeAppctl{}, nil, db, time.Second, coverageFilter{}, 0, []string{"iface-id", "k8s.ovn.org/pod"}, false)
+
+	expected := `
+# HELP ovsdp_interface_mtu MTU of the interface
+# TYPE ovsdp_interface_mtu gauge
+ovsdp_interface_mtu{iface_id="",interface="dpdk0",k8s_ovn_org_pod="",type="dpdk"} 9000
+ovsdp_interface_mtu{iface_id="pod1_ns1",interface="tap1",k8s_ovn_org_pod="ns1/pod1",type=""} 1500
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "ovsdp_interface_mtu")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_ovsDPCollectorMetricPresent(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctl      fakeAppctl
+		expected string
+	}{
+		{
+			name: "found",
+			ctl:  fakeAppctl{"dpctl/ct-get-maxconns": "3000000\n"},
+			expected: `
+# HELP ovsdp_metric_present Whether the metric was found in the output of its OVS command, absent when the command failed
+# TYPE ovsdp_metric_present gauge
+ovsdp_metric_present{metric="ovsdp_ct_max_connections"} 1
+`,
+		},
+		{
+			name: "missing",
+			ctl:  fakeAppctl{"dpctl/ct-get-maxconns": "\n"},
+			expected: `
+# HELP ovsdp_metric_present Whether the metric was found in the output of its OVS command, absent when the command failed
+# TYPE ovsdp_metric_present gauge
+ovsdp_metric_present{metric="ovsdp_ct_max_connections"} 0
+`,
+		},
+		{
+			// Every command fails.
+			name:     "failed",
+			ctl:      fakeAppctl{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := newOvsDPCollector(tt.ctl, nil, nil, time.Second, coverageFilter{}, 0, nil, false)
+			err := testutil.CollectAndCompare(collector, strings.NewReader(tt.expected), "ovsdp_metric_present")
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// slowAppctl delays every run of a command.
+type slowAppctl struct {
+	appctl  appctl
+	command string
+	delay   time.Duration
+}
+
+func (ctl slowAppctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	if command == ctl.command {
+		time.Sleep(ctl.delay)
+	}
+	return ctl.appctl.run(ctx, command, args...)
+}
+
+// gatheredValue returns the value of the gathered counter or gauge name with
+// the label set to value, or false if it wasn't gathered.
+func gatheredValue(families []*dto.MetricFamily, name string, label string, value string) (float64, bool) {
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() != label || pair.GetValue() != value {
+					continue
+				}
+				if metric.Counter != nil {
+					return metric.GetCounter().GetValue(), true
+				}
+				return metric.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func Test_ovsDPCollectorScrapeHealth(t *testing.T) {
+	ctl := slowAppctl{
+		appctl: fakeAppctl{
+			"dpif/show": `netdev@ovs-netdev: hit:0 missed:0
+  br-int:
+    br-int 65534/1: (tap)
+  br-phy:
+    br-phy 65534/2: (tap)
+`,
+			"fdb/stats-show br-int": "  Current/maximum MAC entries in the table: 1/2048\n",
+			"fdb/stats-show br-phy": "  Current/maximum MAC entries in the table: 2/2048\n",
+			// The total is out of range.
+			"coverage/show": "datapath_drop_meter   0.0/sec     0.000/sec        0.0000/sec   total: 1" + strings.Repeat("0", 400) + "\n",
+		},
+		command: fdbStatsCommand,
+		delay:   20 * time.Millisecond,
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(newOvsDPCollector(ctl, nil, nil, time.Second, coverageFilter{}, 0, nil, false))
+
+	for scrape := 1.0; scrape <= 2; scrape++ {
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("Metrics can't be gathered: %v", err)
+		}
+
+		tests := []struct {
+			name     string
+			metric   string
+			label    string
+			value    string
+			expected float64
+		}{
+			{name: "failed command", metric: "ovsdp_scrape_errors_total", label: "command", value: "upcall/show", expected: scrape},
+			{name: "succeeded command", metric: "ovsdp_scrape_errors_total", label: "command", value: "coverage/show", expected: 0},
+			{name: "malformed value", metric: "ovsdp_metric_parse_failures_total", label: "metric", value: "ovsdp_datapath_drop_meter", expected: scrape},
+		}
+		for _, tt := range tests {
+			v, ok := gatheredValue(families, tt.metric, tt.label, tt.value)
+			if !ok || v != tt.expected {
+				t.Errorf("Scrape %v: expected %s{%s=%q} %v, got %v (gathered %v)", scrape, tt.metric, tt.label, tt.value, tt.expected, v, ok)
+			}
+		}
+
+		// The durations of the commands run per bridge add up.
+		duration, ok := gatheredValue(families, "ovsdp_scrape_duration_seconds", "command", fdbStatsCommand)
+		if !ok || duration < 2*ctl.delay.Seconds() {
+			t.Errorf("Scrape %v: expected the fdb/stats-show duration to be at least %v, got %v (gathered %v)", scrape, 2*ctl.delay.Seconds(), duration, ok)
+		}
+	}
+}
+
+func Test_ovsDPCollectorBeforeFirstPoll(t *testing.T) {
+	collector := newOvsDPCollector(fakeAppctl{}, nil, nil, time.Second, coverageFilter{}, 0, nil, false)
+	collector.polling = true
+	// The first poll is still running, e.g. every command timing out.
+	collector.pollingStart = time.Now().Add(-3 * time.Minute)
+
+	ovsMetric, age := collector.cachedSnapshot()
+	if len(ovsMetric.Commands) != 0 {
+		t.Errorf("Expected an empty snapshot, got %v", ovsMetric.Commands)
+	}
+	if age < 3*time.Minute {
+		t.Errorf("Expected the age to count since polling started, got %s", age)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// FDBMetric holds the MAC learning table stats of a bridge from
+// fdb/stats-show.
+type FDBMetric struct {
+	Bridge string
+	// Values hold "current", "maximum" and "static" entries, and the
+	// "learned", "expired", "evicted" and "moved" totals.
+	Values map[string]float64
+}
+
+// fdbSamples returns the values of an fdb/stats-show registry metric.
+func (metrics *OvsMetric) fdbSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, fdb := range metrics.FDBs {
+		if v, ok := fdb.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{fdb.Bridge}})
+		}
+	}
+	return samples
+}
+
+func parseFDBStats(metrics *OvsMetric, bridge string, fdbStats string) {
+	entriesRegexp := regexp.MustCompile(`(?m)^[ \t]*Current/maximum MAC entries in the table\s*:\s*(\d+)/(\d+)`)
+	staticRegexp := regexp.MustCompile(`(?m)^[ \t]*Current static MAC entries in the table\s*:\s*(\d+)`)
+	// e.g. "Total number of port moved MAC entries  : 0"
+	totalRegexp := regexp.MustCompile(`(?m)^[ \t]*Total number of (?:port )?(\w+) MAC entries\s*:\s*(\d+)`)
+
+	fdb := FDBMetric{Bridge: bridge, Values: make(map[string]float64)}
+	parse := func(source string, value string) {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			metrics.parseFailure(fdbStatsCommand, source)
+			return
+		}
+		fdb.Values[source] = v
+	}
+
+	if match := entriesRegexp.FindStringSubmatch(fdbStats); match != nil {
+		parse("current", match[1])
+		parse("maximum", match[2])
+	}
+	if match := staticRegexp.FindStringSubmatch(fdbStats); match != nil {
+		parse("static", match[1])
+	}
+	for _, match := range totalRegexp.FindAllStringSubmatch(fdbStats, -1) {
+		parse(match[1], match[2])
+	}
+
+	metrics.FDBs = append(metrics.FDBs, fdb)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func Test_parseFDBStats(t *testing.T) {
+	var ovsMetric OvsMetric
+	parseFDBStats(&ovsMetric, "br0", `Statistics for bridge "br0":
+  Current/maximum MAC entries in the table: 4/8192
+  Current static MAC entries in the table : 1
+  Total number of learned MAC entries     : 12
+  Total number of expired MAC entries     : 6
+  Total number of evicted MAC entries     : 2
+  Total number of port moved MAC entries  : 3
+`)
+
+	expected := OvsMetric{
+		FDBs: []FDBMetric{
+			{
+				Bridge: "br0",
+				Values: map[string]float64{"current": 4, "maximum": 8192, "static": 1, "learned": 12, "expired": 6, "evicted": 2, "moved": 3},
+			},
+		},
+	}
+	diff := cmp.Diff(ovsMetric, expected)
+	if diff != "" {
+		t.Errorf("Structs are different:\n%s", diff)
+	}
+}
+
+func Test_getOvsMetricBridges(t *testing.T) {
+	ctl := fakeAppctl{
+		"dpif/show": `netdev@ovs-netdev: hit:0 missed:0
+  br-int:
+    br-int 65534/1: (tap)
+  br-phy:
+    br-phy 65534/2: (tap)
+`,
+		"fdb/stats-show br-phy": `Statistics for bridge "br-phy":
+  Current/maximum MAC entries in the table: 2/2048
+`,
+	}
+	ovsMetric := getOvsMetric(context.Background(), ctl, nil, nil)
+
+	expected := []FDBMetric{
+		{Bridge: "br-phy", Values: map[string]float64{"current": 2, "maximum": 2048}},
+	}
+	diff := cmp.Diff(ovsMetric.FDBs, expected)
+	if diff != "" {
+		t.Errorf("FDBs are different:\n%s", diff)
+	}
+	if !ovsMetric.succeeded(fdbStatsCommand) {
+		t.Error("Expected fdb/stats-show to succeed for one bridge")
+	}
+
+	// Commands run per bridge are reported once.
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(newOvsDPCollector(ctl, nil, nil, time.Second, coverageFilter{}, 0, nil, false))
+	if _, err := registry.Gather(); err != nil {
+		t.Errorf("Metrics can't be gathered: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// interfaceColumns are the columns of the Interface table read by
+// parseInterfaces.
+var interfaceColumns = []string{"name", "type", "statistics", "link_state", "mtu", "external_ids", "bfd_status", "cfm_flap_count"}
+
+// InterfaceMetric holds an interface of the OVSDB Interface table.
+type InterfaceMetric struct {
+	Name string
+	// Type is empty for system interfaces.
+	Type        string
+	ExternalIDs map[string]string
+	// Values hold the statistics column counters keyed by name, e.g.
+	// "rx_packets" or "rx_missed_errors", and "mtu", "link_state",
+	// "bfd flap_count" and "cfm_flap_count" when set.
+	Values map[string]float64
+}
+
+// interfaceSamples returns the values of an Interface table registry metric.
+func (metrics *OvsMetric) interfaceSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, iface := range metrics.Interfaces {
+		if v, ok := iface.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{iface.Name, iface.Type}})
+		}
+	}
+	return samples
+}
+
+func parseInterfaces(metrics *OvsMetric, rows []ovsdbRow) {
+	metrics.Interfaces = nil
+	for _, row := range rows {
+		var iface InterfaceMetric
+		if json.Unmarshal(row["name"], &iface.Name) != nil {
+			continue
+		}
+		json.Unmarshal(row["type"], &iface.Type)
+		iface.Values = make(map[string]float64)
+
+		if statistics, err := ovsdbMap(row["statistics"]); err == nil {
+			for _, pair := range statistics {
+				var name string
+				var v float64
+				if json.Unmarshal(pair[0], &name) != nil {
+					continue
+				}
+				if json.Unmarshal(pair[1], &v) != nil {
+					metrics.parseFailure(interfaceCommand, name)
+					continue
+				}
+				iface.Values[name] = v
+			}
+		}
+
+		if atoms, err := ovsdbAtoms(row["link_state"]); err == nil && len(atoms) == 1 {
+			var linkState string
+			if json.Unmarshal(atoms[0], &linkState) != nil {
+				metrics.parseFailure(interfaceCommand, "link_state")
+			} else {
+				iface.Values["link_state"] = boolValue(linkState == "up")
+			}
+		}
+		if atoms, err := ovsdbAtoms(row["mtu"]); err == nil && len(atoms) == 1 {
+			var mtu float64
+			if json.Unmarshal(atoms[0], &mtu) != nil {
+				metrics.parseFailure(interfaceCommand, "mtu")
+			} else {
+				iface.Values["mtu"] = mtu
+			}
+		}
+
+		if bfdStatus, err := ovsdbMap(row["bfd_status"]); err == nil {
+			for _, pair := range bfdStatus {
+				var key, value string
+				if json.Unmarshal(pair[0], &key) != nil || key != "flap_count" {
+					continue
+				}
+				if json.Unmarshal(pair[1], &value) != nil {
+					metrics.parseFailure(interfaceCommand, "bfd flap_count")
+					continue
+				}
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					metrics.parseFailure(interfaceCommand, "bfd flap_count")
+					continue
+				}
+				iface.Values["bfd flap_count"] = v
+			}
+		}
+		if atoms, err := ovsdbAtoms(row["cfm_flap_count"]); err == nil && len(atoms) == 1 {
+			var flaps float64
+			if json.Unmarshal(atoms[0], &flaps) != nil {
+				metrics.parseFailure(interfaceCommand, "cfm_flap_count")
+			} else {
+				iface.Values["cfm_flap_count"] = flaps
+			}
+		}
+
+		if externalIDs, err := ovsdbMap(row["external_ids"]); err == nil && len(externalIDs) > 0 {
+			iface.ExternalIDs = make(map[string]string, len(externalIDs))
+			for _, pair := range externalIDs {
+				var key, value string
+				if json.Unmarshal(pair[0], &key) == nil && json.Unmarshal(pair[1], &value) == nil {
+					iface.ExternalIDs[key] = value
+				}
+			}
+		}
+
+		metrics.Interfaces = append(metrics.Interfaces, iface)
+	}
+}
+
+// externalIDLabel returns the label name of an external_ids key, replacing
+// the characters invalid in label names like "-" or "/" with "_".
+func externalIDLabel(key string) string {
+	label := regexp.MustCompile(`[^a-zA-Z0-9_]`).ReplaceAllString(key, "_")
+	if label == "" || label[0] >= '0' && label[0] <= '9' {
+		label = "_" + label
+	}
+	return label
+}
+
+// externalIDLabels returns the label names of the external_ids keys, failing
+// when a key maps to an invalid or reserved label, or two keys map to the
+// same label or to an interface label.
+func externalIDLabels(keys []string) ([]string, error) {
+	used := make(map[string]string)
+	for _, label := range interfaceLabels {
+		used[label] = label
+	}
+
+	var labels []string
+	for _, key := range keys {
+		label := externalIDLabel(key)
+		if strings.HasPrefix(label, "__") || !model.LabelName(label).IsValid() {
+			return nil, fmt.Errorf("external_ids key %q has the invalid label %s", key, label)
+		}
+		if other, ok := used[label]; ok {
+			return nil, fmt.Errorf("external_ids key %q has the same label %s as %q", key, label, other)
+		}
+		used[label] = key
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseInterfaces(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			rows: `[
+  {
+    "name": "dpdk0",
+    "type": "dpdk",
+    "statistics": ["map", [["rx_packets", 1000], ["tx_packets", 900], ["rx_missed_errors", 12], ["ovs_tx_qos_drops", 0]]],
+    "link_state": "up",
+    "mtu": 9000,
+    "external_ids": ["map", []],
+    "bfd_status": ["map", [["state", "up"], ["forwarding", "true"], ["flap_count", "3"]]],
+    "cfm_flap_count": 1
+  },
+  {
+    "name": "vhu7f3a2c1e-4b",
+    "type": "dpdkvhostuserclient",
+    "statistics": ["map", [["rx_bytes", 4096]]],
+    "link_state": "down",
+    "mtu": ["set", []],
+    "external_ids": ["map", [["iface-id", "7f3a2c1e-4b1d"], ["attached-mac", "0a:58:0a:80:00:05"]]]
+  },
+  {
+    "name": "br-int",
+    "type": "internal",
+    "statistics": ["map", []],
+    "link_state": ["set", []],
+    "mtu": ["set", []],
+    "external_ids": ["map", []]
+  }
+]`,
+			metric: OvsMetric{
+				Interfaces: []InterfaceMetric{
+					{
+						Name:   "dpdk0",
+						Type:   "dpdk",
+						Values: map[string]float64{"rx_packets": 1000, "tx_packets": 900, "rx_missed_errors": 12, "ovs_tx_qos_drops": 0, "link_state": 1, "mtu": 9000, "bfd flap_count": 3, "cfm_flap_count": 1},
+					},
+					{
+						Name:        "vhu7f3a2c1e-4b",
+						Type:        "dpdkvhostuserclient",
+						ExternalIDs: map[string]string{"iface-id": "7f3a2c1e-4b1d", "attached-mac": "0a:58:0a:80:00:05"},
+						Values:      map[string]float64{"rx_bytes": 4096, "link_state": 0},
+					},
+					{
+						Name:   "br-int",
+						Type:   "internal",
+						Values: map[string]float64{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []ovsdbRow
+			if err := json.Unmarshal([]byte(tt.rows), &rows); err != nil {
+				t.Fatal(err)
+			}
+			var ovsMetric OvsMetric
+			parseInterfaces(&ovsMetric, rows)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_externalIDLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string
+		labels []string
+		err    bool
+	}{
+		{
+			name:   "valid",
+			keys:   []string{"iface-id", "k8s.ovn.org/pod", "attached_mac"},
+			labels: []string{"iface_id", "k8s_ovn_org_pod", "attached_mac"},
+		},
+		{
+			name:   "leading digit",
+			keys:   []string{"0id"},
+			labels: []string{"_0id"},
+		},
+		{
+			name: "reserved label",
+			keys: []string{"--pod"},
+			err:  true,
+		},
+		{
+			name: "same label",
+			keys: []string{"iface-id", "iface_id"},
+			err:  true,
+		},
+		{
+			name: "interface label",
+			keys: []string{"type"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := externalIDLabels(tt.keys)
+			if (err != nil) != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+
+			diff := cmp.Diff(labels, tt.labels)
+			if diff != "" {
+				t.Errorf("Labels are different:\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -11,18 +17,99 @@ import (
 
 func main() {
 	var (
-		host     = flag.String("metrics.host", ":9000", "URL host for OVS datapath exporter")
-		pathname = flag.String("metrics.pathname", "/metrics", "URL pathname exposing the collected metrics")
+		host            = flag.String("metrics.host", ":9000", "URL host for OVS datapath exporter")
+		pathname        = flag.String("metrics.pathname", "/metrics", "URL pathname exposing the collected metrics")
+		coverageAllow   = flag.String("coverage.allow", "", "Regexp of coverage/show counters exported as ovsdp_coverage_total and ovsdp_coverage_rate, all counters if empty")
+		coverageDeny    = flag.String("coverage.deny", "", "Regexp of coverage/show counters excluded from ovsdp_coverage_total and ovsdp_coverage_rate")
+		ctMaxZones      = flag.Int("conntrack.max-zones", 1000, "Maximum number of conntrack zones exported by ovsdp_ct_zone_limit and ovsdp_ct_zone_count, those closest to their limit, all zones if 0")
+		tunnelNeighbors = flag.Bool("tunnel.neighbors", false, "Export every tunnel neighbor cache entry as ovsdp_tunnel_neighbor, in addition to their number by bridge and state")
+		appctlPath      = flag.String("ovs.appctl", "/usr/bin/ovs-appctl", "Path of the ovs-appctl binary")
+		useUnixctl      = flag.Bool("ovs.unixctl", false, "Talk JSON-RPC to the ovs-vswitchd unixctl socket instead of forking ovs-appctl, which is still used if the socket can't be reached")
+		ofctlPath       = flag.String("ovs.ofctl", "", "Path of the ovs-ofctl binary run per bridge for the OpenFlow table, port and meter stats, e.g. /usr/bin/ovs-ofctl, not run if empty")
+		rundir          = flag.String("ovs.rundir", "/var/run/openvswitch", "Directory holding the ovs-vswitchd pidfile and unixctl socket")
+		dbSocket        = flag.String("ovs.db", "/var/run/openvswitch/db.sock", "Unix socket of ovsdb-server to read the Interface table from, not read if empty")
+		externalIDs     = flag.String("interface.external-ids", "", "Comma-separated Interface external_ids keys added as labels of the interface metrics, e.g. iface-id, with the characters invalid in label names replaced by _")
+		timeout         = flag.Duration("ovs.timeout", 5*time.Second, "Timeout of each OVS command, also bounded by the Prometheus scrape timeout")
+		pollInterval    = flag.Duration("ovs.poll-interval", 0, "Interval of polling OVS in the background and serving scrapes from the last snapshot, disabled if 0")
+		maxStaleness    = flag.Duration("ovs.max-staleness", 0, "Age after which a polled snapshot is withheld from scrapes, never if 0")
+		timeoutOffset   = flag.Duration("metrics.timeout-offset", 500*time.Millisecond, "Offset subtracted from the Prometheus scrape timeout to leave time to send the response")
 	)
 
 	flag.Parse()
 
-	registry := prometheus.NewRegistry()
-	collector := newOvsDPCollector()
-	registry.MustRegister(collector)
+	var coverage coverageFilter
+	var err error
+	if *coverageAllow != "" {
+		if coverage.allow, err = regexp.Compile("^(?:" + *coverageAllow + ")$"); err != nil {
+			fmt.Printf("Invalid -coverage.allow: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *coverageDeny != "" {
+		if coverage.deny, err = regexp.Compile("^(?:" + *coverageDeny + ")$"); err != nil {
+			fmt.Printf("Invalid -coverage.deny: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var externalIDKeys []string
+	if *externalIDs != "" {
+		for _, key := range strings.Split(*externalIDs, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				externalIDKeys = append(externalIDKeys, key)
+			}
+		}
+		if _, err := externalIDLabels(externalIDKeys); err != nil {
+			fmt.Printf("Invalid -interface.external-ids: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var ctl appctl = execAppctl{path: *appctlPath}
+	if *useUnixctl {
+		ctl = unixctlClient{rundir: *rundir, target: "ovs-vswitchd", fallback: ctl}
+	}
+
+	var ofctl appctl
+	if *ofctlPath != "" {
+		ofctl = execOfctl{path: *ofctlPath}
+	}
+
+	var db ovsdb
+	if *dbSocket != "" {
+		db = ovsdbClient{socket: *dbSocket}
+	}
+
+	collector := newOvsDPCollector(ctl, ofctl, db, *timeout, coverage, *ctMaxZones, externalIDKeys, *tunnelNeighbors)
+	if *pollInterval > 0 {
+		collector.startPolling(*pollInterval, *maxStaleness)
+	}
 
 	fmt.Printf("Starting server listening: %s\n", *host)
-	http.Handle(*pathname, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	http.HandleFunc(*pathname, func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := scrapeContext(r, *timeoutOffset)
+		defer cancel()
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(scrapeCollector{ovsDPCollector: collector, ctx: ctx})
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
 	http.ListenAndServe(*host, nil)
 
 }
+
+// scrapeContext returns the context of a scrape, bounded by the timeout
+// Prometheus sends in the X-Prometheus-Scrape-Timeout-Seconds header minus
+// offset.
+func scrapeContext(r *http.Request, offset time.Duration) (context.Context, context.CancelFunc) {
+	seconds, err := strconv.ParseFloat(r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"), 64)
+	if err != nil || seconds <= 0 {
+		return context.WithCancel(r.Context())
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > offset {
+		timeout -= offset
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_scrapeContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		offset   time.Duration
+		deadline bool
+		timeout  time.Duration
+	}{
+		{
+			name:   "missing header",
+			offset: 500 * time.Millisecond,
+		},
+		{
+			name:   "malformed header",
+			header: "ten",
+			offset: 500 * time.Millisecond,
+		},
+		{
+			name:   "zero timeout",
+			header: "0",
+			offset: 500 * time.Millisecond,
+		},
+		{
+			name:     "offset larger than timeout",
+			header:   "0.2",
+			offset:   500 * time.Millisecond,
+			deadline: true,
+			timeout:  200 * time.Millisecond,
+		},
+		{
+			name:     "timeout",
+			header:   "10",
+			offset:   500 * time.Millisecond,
+			deadline: true,
+			timeout:  9500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", tt.header)
+			}
+
+			start := time.Now()
+			ctx, cancel := scrapeContext(r, tt.offset)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != tt.deadline {
+				t.Fatalf("Expected a deadline %v, got %v", tt.deadline, ok)
+			}
+			if !ok {
+				return
+			}
+			if timeout := deadline.Sub(start); timeout < tt.timeout || timeout > tt.timeout+time.Second {
+				t.Errorf("Expected a timeout of %s, got %s", tt.timeout, timeout)
+			}
+		})
+	}
+}
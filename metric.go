@@ -1,552 +1,489 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
-type OvsMetric struct {
-	// PMD stats
-	MissWithSuccessUpcall      float64
-	MissWithFailedUpcall       float64
-	AvgSubtableLookupsMegaflow float64
-	ProcessingCycles           float64
-	IdleCycles                 float64
-	// Drop reasons
-	UpcallDrops                      float64
-	UpcallDropsLockError             float64
-	RxDropsInvalidPacket             float64
-	DatapathDropMeter                float64
-	DatapathDropUserspaceActionError float64
-	DatapathDropTunnelPushError      float64
-	DatapathDropTunnelPopError       float64
-	DatapathDropRecircError          float64
-	DatapathDropInvalidPort          float64
-	DatapathDropInvalidTnlPort       float64
-	DatapathDropSampleError          float64
-	DatapathDropNshDecapError        float64
-	DropActionOfPipeline             float64
-	DropActionBridgeNotFound         float64
-	DropActionRecursionTooDeep       float64
-	DropActionTooManyResubmit        float64
-	DropActionStackTooDeep           float64
-	DropActionNoRecirculationContext float64
-	DropActionRecirculationConflict  float64
-	DropActionTooManyMplsLabels      float64
-	DropActionInvalidTunnelMetadata  float64
-	DropActionUnsupportedPacketType  float64
-	DropActionCongestion             float64
-	DropActionForwardingDisabled     float64
-	// Drop reasons new
-	NetdevVxlanTsoDrops         float64
-	NetdevGeneveTsoDrops        float64
-	NetdevPushHeaderDrops       float64
-	NetdevSoftSegDrops          float64
-	DatapathDropTunnelTsoRecirc float64
-	DatapathDropInvalidBond     float64
-	DatapathDropHwMissRecover   float64
-	// DOCA
-	OvsDocaNoMark              float64
-	OvsDocaInvalidClassifyPort float64
-	DocaQueueEmpty             float64
-	DocaQueueNoneProcessed     float64
-	DocaResizeBlock            float64
-	DocaPipeResize             float64
-	DocaPipeResizeOver10Ms     float64
+// PMDThreadMetric holds the stats of one thread section of pmd-stats-show,
+// either a PMD thread or the main thread.
+type PMDThreadMetric struct {
+	Thread string
+	NumaID string
+	CoreID string
+	// Values are keyed by the text before the colon of each line. The
+	// percentage in parentheses of a line is keyed with a " %" suffix.
+	Values map[string]float64
 }
 
-func getOvsMetric() *OvsMetric {
-	var ovsMetric OvsMetric
+// CoverageCounter is a single counter line of coverage/show. The rates are
+// the average events per second over the last 5 seconds, minute and hour.
+type CoverageCounter struct {
+	Name      string
+	Rate5s    float64
+	Rate60s   float64
+	Rate3600s float64
+	Total     float64
+}
 
-	cmd := exec.Command("/usr/bin/ovs-appctl", "dpif-netdev/pmd-stats-show")
-	pmdStatsOutput, err := cmd.CombinedOutput()
-	if err != nil {
-		fmt.Printf("Error running command: %v\n", err)
-	} else {
-		parsePMDStats(&ovsMetric, string(pmdStatsOutput))
-	}
+type OvsMetric struct {
+	// PMD stats
+	PMDThreads []PMDThreadMetric
+	// Every coverage/show counter
+	Coverage []CoverageCounter
+	// PMD rx queues
+	PMDRxqs []PMDRxqMetric
+	// PMD performance
+	PMDPerfs []PMDPerfMetric
+	// Conntrack
+	Conntrack ConntrackMetric
+	// Every ct-get-limits zone
+	ConntrackZones []ConntrackZone
+	// Upcalls
+	Upcalls []UpcallMetric
+	// Datapaths
+	Datapaths []DatapathMetric
+	Bridges   []BridgeMetric
+	// Hardware offload
+	Offload OffloadMetric
+	// DPDK memory
+	DPDKMempools []DPDKMempoolMetric
+	DPDKHeaps    []DPDKHeapMetric
+	// Bonds
+	Bonds []BondMetric
+	LACPs []LACPMetric
+	// BFD and CFM
+	BFDs []BFDMetric
+	CFMs []CFMMetric
+	// Tunnels
+	TunnelPorts     []TunnelPortMetric
+	TunnelNeighbors []TunnelNeighbor
+	// MAC learning, per bridge
+	FDBs []FDBMetric
+	// OpenFlow, per bridge
+	OpenFlowTables []OpenFlowTableMetric
+	OpenFlowPorts  []OpenFlowPortMetric
+	Meters         []MeterMetric
+	// OVSDB Interface table
+	Interfaces []InterfaceMetric
+	// Scrape health
+	Commands      []CommandResult
+	ParseFailures []string
+}
 
-	cmd = exec.Command("/usr/bin/ovs-appctl", "coverage/show")
-	coverageOutput, err := cmd.CombinedOutput()
-	if err != nil {
-		fmt.Printf("Error running command: %v\n", err)
-	} else {
-		parseCoverageDropReasons(&ovsMetric, string(coverageOutput))
-		parseCoverageDoca(&ovsMetric, string(coverageOutput))
-	}
+// CommandResult records how running an OVS command went during a scrape.
+type CommandResult struct {
+	Command  string
+	Duration time.Duration
+	Err      error
+}
 
-	return &ovsMetric
+// Sample is a value of a registry metric with its label values. Histogram
+// samples also carry their count and cumulative buckets, Value being the sum.
+type Sample struct {
+	Value   float64
+	Count   uint64
+	Buckets map[float64]uint64
+	Labels  []string
 }
 
-func parseCoverageDoca(metrics *OvsMetric, coverageStats string) {
-	// DOCA
-	ovsDocaNoMarkRegexp := regexp.MustCompile(`(?m)^[ \t]*ovs_doca_no_mark.*total:\s*(\d+)`)
-	ovsDocaNoMarkMatch := ovsDocaNoMarkRegexp.FindStringSubmatch(coverageStats)
-	metrics.OvsDocaNoMark = -1
-	if len(ovsDocaNoMarkMatch) > 1 {
-		v, err := strconv.ParseFloat(ovsDocaNoMarkMatch[1], 64)
-		if err == nil {
-			metrics.OvsDocaNoMark = v
-		}
-	}
+func getOvsMetric(ctx context.Context, ctl appctl, ofctl appctl, db ovsdb) *OvsMetric {
+	var ovsMetric OvsMetric
 
-	ovsDocaInvalidClassifyPortRegexp := regexp.MustCompile(`(?m)^[ \t]*ovs_doca_invalid_classify_port.*total:\s*(\d+)`)
-	ovsDocaInvalidClassifyPortMatch := ovsDocaInvalidClassifyPortRegexp.FindStringSubmatch(coverageStats)
-	metrics.OvsDocaInvalidClassifyPort = -1
-	if len(ovsDocaInvalidClassifyPortMatch) > 1 {
-		v, err := strconv.ParseFloat(ovsDocaInvalidClassifyPortMatch[1], 64)
-		if err == nil {
-			metrics.OvsDocaInvalidClassifyPort = v
-		}
+	pmdStatsOutput, ok := ovsMetric.runCommand(ctx, ctl, pmdStatsCommand)
+	if ok {
+		parsePMDStats(&ovsMetric, pmdStatsOutput)
 	}
 
-	docaQueueEmptyRegexp := regexp.MustCompile(`(?m)^[ \t]*doca_queue_empty.*total:\s*(\d+)`)
-	docaQueueEmptyMatch := docaQueueEmptyRegexp.FindStringSubmatch(coverageStats)
-	metrics.DocaQueueEmpty = -1
-	if len(docaQueueEmptyMatch) > 1 {
-		v, err := strconv.ParseFloat(docaQueueEmptyMatch[1], 64)
-		if err == nil {
-			metrics.DocaQueueEmpty = v
-		}
+	coverageOutput, ok := ovsMetric.runCommand(ctx, ctl, coverageCommand)
+	if ok {
+		parseCoverage(&ovsMetric, coverageOutput)
 	}
 
-	docaQueueNoneProcessedRegexp := regexp.MustCompile(`(?m)^[ \t]*doca_queue_none_processed.*total:\s*(\d+)`)
-	docaQueueNoneProcessedMatch := docaQueueNoneProcessedRegexp.FindStringSubmatch(coverageStats)
-	metrics.DocaQueueNoneProcessed = -1
-	if len(docaQueueNoneProcessedMatch) > 1 {
-		v, err := strconv.ParseFloat(docaQueueNoneProcessedMatch[1], 64)
-		if err == nil {
-			metrics.DocaQueueNoneProcessed = v
-		}
+	pmdRxqOutput, ok := ovsMetric.runCommand(ctx, ctl, pmdRxqCommand)
+	if ok {
+		parsePMDRxq(&ovsMetric, pmdRxqOutput)
 	}
 
-	docaResizeBlockRegexp := regexp.MustCompile(`(?m)^[ \t]*doca_resize_block.*total:\s*(\d+)`)
-	docaResizeBlockMatch := docaResizeBlockRegexp.FindStringSubmatch(coverageStats)
-	metrics.DocaResizeBlock = -1
-	if len(docaResizeBlockMatch) > 1 {
-		v, err := strconv.ParseFloat(docaResizeBlockMatch[1], 64)
-		if err == nil {
-			metrics.DocaResizeBlock = v
-		}
+	pmdPerfOutput, ok := ovsMetric.runCommand(ctx, ctl, pmdPerfCommand)
+	if ok {
+		parsePMDPerf(&ovsMetric, pmdPerfOutput)
 	}
 
-	docaPipeResizeRegexp := regexp.MustCompile(`(?m)^[ \t]*doca_pipe_resize.*total:\s*(\d+)`)
-	docaPipeResizeMatch := docaPipeResizeRegexp.FindStringSubmatch(coverageStats)
-	metrics.DocaPipeResize = -1
-	if len(docaPipeResizeMatch) > 1 {
-		v, err := strconv.ParseFloat(docaPipeResizeMatch[1], 64)
-		if err == nil {
-			metrics.DocaPipeResize = v
-		}
+	ctStatsOutput, ok := ovsMetric.runCommand(ctx, ctl, ctStatsCommand, "-m")
+	if ok {
+		parseConntrackStats(&ovsMetric, ctStatsOutput)
 	}
 
-	docaPipeResizeOver10MsRegexp := regexp.MustCompile(`(?m)^[ \t]*doca_pipe_resize_over_10_ms.*total:\s*(\d+)`)
-	docaPipeResizeOver10MsMatch := docaPipeResizeOver10MsRegexp.FindStringSubmatch(coverageStats)
-	metrics.DocaPipeResizeOver10Ms = -1
-	if len(docaPipeResizeOver10MsMatch) > 1 {
-		v, err := strconv.ParseFloat(docaPipeResizeOver10MsMatch[1], 64)
-		if err == nil {
-			metrics.DocaPipeResizeOver10Ms = v
-		}
+	ctNConnsOutput, ok := ovsMetric.runCommand(ctx, ctl, ctNConnsCommand)
+	if ok {
+		parseConntrackValue(&ovsMetric, ctNConnsCommand, "connections", ctNConnsOutput)
 	}
-}
 
-func parseCoverageDropReasons(metrics *OvsMetric, coverageStats string) {
-	// Drop reasons
-	// Upcall drops
-	upcallDropsRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_upcall_error.*total:\s*(\d+)`)
-	upcallDropsMatch := upcallDropsRegexp.FindStringSubmatch(coverageStats)
-	metrics.UpcallDrops = -1
-	if len(upcallDropsMatch) > 1 {
-		v, err := strconv.ParseFloat(upcallDropsMatch[1], 64)
-		if err == nil {
-			metrics.UpcallDrops = v
-		}
+	ctMaxConnsOutput, ok := ovsMetric.runCommand(ctx, ctl, ctMaxConnsCommand)
+	if ok {
+		parseConntrackValue(&ovsMetric, ctMaxConnsCommand, "max connections", ctMaxConnsOutput)
 	}
 
-	// Upcall drops lock error
-	upcallDropsLockErrorRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_lock_error.*total:\s*(\d+)`)
-	upcallDropsLockErrorMatch := upcallDropsLockErrorRegexp.FindStringSubmatch(coverageStats)
-	metrics.UpcallDropsLockError = -1
-	if len(upcallDropsLockErrorMatch) > 1 {
-		v, err := strconv.ParseFloat(upcallDropsLockErrorMatch[1], 64)
-		if err == nil {
-			metrics.UpcallDropsLockError = v
-		}
+	ctLimitsOutput, ok := ovsMetric.runCommand(ctx, ctl, ctLimitsCommand)
+	if ok {
+		parseConntrackLimits(&ovsMetric, ctLimitsOutput)
 	}
 
-	// RX drops invalid packet
-	rxDropsInvalidPacketRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_rx_invalid_packet.*total:\s*(\d+)`)
-	rxDropsInvalidPacketMatch := rxDropsInvalidPacketRegexp.FindStringSubmatch(coverageStats)
-	metrics.RxDropsInvalidPacket = -1
-	if len(rxDropsInvalidPacketMatch) > 1 {
-		v, err := strconv.ParseFloat(rxDropsInvalidPacketMatch[1], 64)
-		if err == nil {
-			metrics.RxDropsInvalidPacket = v
-		}
+	upcallOutput, ok := ovsMetric.runCommand(ctx, ctl, upcallCommand)
+	if ok {
+		parseUpcall(&ovsMetric, upcallOutput)
 	}
 
-	// Datapath drop meter
-	datapathDropMeterRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_meter.*total:\s*(\d+)`)
-	datapathDropMeterMatch := datapathDropMeterRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropMeter = -1
-	if len(datapathDropMeterMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropMeterMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropMeter = v
-		}
+	dpctlShowOutput, ok := ovsMetric.runCommand(ctx, ctl, dpctlShowCommand)
+	if ok {
+		parseDatapaths(&ovsMetric, dpctlShowOutput)
 	}
 
-	// Datapath drop userspace action error
-	datapathDropUserspaceActionErrorRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_userspace_action_error.*total:\s*(\d+)`)
-	datapathDropUserspaceActionErrorMatch := datapathDropUserspaceActionErrorRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropUserspaceActionError = -1
-	if len(datapathDropUserspaceActionErrorMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropUserspaceActionErrorMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropUserspaceActionError = v
-		}
+	dpifShowOutput, ok := ovsMetric.runCommand(ctx, ctl, dpifShowCommand)
+	if ok {
+		parseBridges(&ovsMetric, dpifShowOutput)
 	}
 
-	// Datapath drop tunnel push error
-	datapathDropTunnelPushErrorRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_tunnel_push_error.*total:\s*(\d+)`)
-	datapathDropTunnelPushErrorMatch := datapathDropTunnelPushErrorRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropTunnelPushError = -1
-	if len(datapathDropTunnelPushErrorMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropTunnelPushErrorMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropTunnelPushError = v
-		}
+	offloadStatsOutput, ok := ovsMetric.runOptionalCommand(ctx, ctl, offloadUnsupported, offloadStatsCommand)
+	if ok {
+		parseOffload(&ovsMetric, offloadStatsOutput)
 	}
 
-	// Datapath drop tunnel pop error
-	datapathDropTunnelPopErrorRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_tunnel_pop_error.*total:\s*(\d+)`)
-	datapathDropTunnelPopErrorMatch := datapathDropTunnelPopErrorRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropTunnelPopError = -1
-	if len(datapathDropTunnelPopErrorMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropTunnelPopErrorMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropTunnelPopError = v
-		}
+	dpdkMempoolOutput, ok := ovsMetric.runOptionalCommand(ctx, ctl, dpdkUnsupported, dpdkMempoolCommand)
+	if ok {
+		parseDPDKMempools(&ovsMetric, dpdkMempoolOutput)
 	}
 
-	// Datapath drop recirc error
-	datapathDropRecircErrorRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_recirc_error.*total:\s*(\d+)`)
-	datapathDropRecircErrorMatch := datapathDropRecircErrorRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropRecircError = -1
-	if len(datapathDropRecircErrorMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropRecircErrorMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropRecircError = v
-		}
+	dpdkMallocOutput, ok := ovsMetric.runOptionalCommand(ctx, ctl, dpdkUnsupported, dpdkMallocCommand)
+	if ok {
+		parseDPDKMalloc(&ovsMetric, dpdkMallocOutput)
 	}
 
-	// Datapath drop invalid port
-	datapathDropInvalidPortRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_invalid_port.*total:\s*(\d+)`)
-	datapathDropInvalidPortMatch := datapathDropInvalidPortRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropInvalidPort = -1
-	if len(datapathDropInvalidPortMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropInvalidPortMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropInvalidPort = v
-		}
+	bondOutput, ok := ovsMetric.runCommand(ctx, ctl, bondCommand)
+	if ok {
+		parseBonds(&ovsMetric, bondOutput)
 	}
 
-	// Datapath drop invalid tunnel port
-	datapathDropInvalidTnlPortRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_invalid_tnl_port.*total:\s*(\d+)`)
-	datapathDropInvalidTnlPortMatch := datapathDropInvalidTnlPortRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropInvalidTnlPort = -1
-	if len(datapathDropInvalidTnlPortMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropInvalidTnlPortMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropInvalidTnlPort = v
-		}
+	lacpOutput, ok := ovsMetric.runCommand(ctx, ctl, lacpCommand)
+	if ok {
+		parseLACP(&ovsMetric, lacpOutput)
 	}
 
-	// Datapath drop sample error
-	datapathDropSampleErrorRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_sample_error.*total:\s*(\d+)`)
-	datapathDropSampleErrorMatch := datapathDropSampleErrorRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropSampleError = -1
-	if len(datapathDropSampleErrorMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropSampleErrorMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropSampleError = v
-		}
+	bfdOutput, ok := ovsMetric.runCommand(ctx, ctl, bfdCommand)
+	if ok {
+		parseBFD(&ovsMetric, bfdOutput)
 	}
 
-	// Datapath drop NSH decap error
-	datapathDropNshDecapErrorRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_nsh_decap_error.*total:\s*(\d+)`)
-	datapathDropNshDecapErrorMatch := datapathDropNshDecapErrorRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropNshDecapError = -1
-	if len(datapathDropNshDecapErrorMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropNshDecapErrorMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropNshDecapError = v
-		}
+	cfmOutput, ok := ovsMetric.runCommand(ctx, ctl, cfmCommand)
+	if ok {
+		parseCFM(&ovsMetric, cfmOutput)
 	}
 
-	// Drop action of pipeline
-	dropActionOfPipelineRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_of_pipeline.*total:\s*(\d+)`)
-	dropActionOfPipelineMatch := dropActionOfPipelineRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionOfPipeline = -1
-	if len(dropActionOfPipelineMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionOfPipelineMatch[1], 64)
-		if err == nil {
-			metrics.DropActionOfPipeline = v
-		}
+	tunnelPortsOutput, ok := ovsMetric.runCommand(ctx, ctl, tunnelPortsCommand)
+	if ok {
+		parseTunnelPorts(&ovsMetric, tunnelPortsOutput)
 	}
 
-	// Drop action bridge not found
-	dropActionBridgeNotFoundRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_bridge_not_found.*total:\s*(\d+)`)
-	dropActionBridgeNotFoundMatch := dropActionBridgeNotFoundRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionBridgeNotFound = -1
-	if len(dropActionBridgeNotFoundMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionBridgeNotFoundMatch[1], 64)
-		if err == nil {
-			metrics.DropActionBridgeNotFound = v
-		}
+	tunnelNeighborsOutput, ok := ovsMetric.runCommand(ctx, ctl, tunnelNeighborsCommand)
+	if ok {
+		parseTunnelNeighbors(&ovsMetric, tunnelNeighborsOutput)
 	}
 
-	// Drop action recursion too deep
-	dropActionRecursionTooDeepRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_recursion_too_deep.*total:\s*(\d+)`)
-	dropActionRecursionTooDeepMatch := dropActionRecursionTooDeepRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionRecursionTooDeep = -1
-	if len(dropActionRecursionTooDeepMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionRecursionTooDeepMatch[1], 64)
-		if err == nil {
-			metrics.DropActionRecursionTooDeep = v
+	// The bridges are discovered from dpif/show.
+	for _, bridge := range ovsMetric.Bridges {
+		fdbStatsOutput, ok := ovsMetric.runCommand(ctx, ctl, fdbStatsCommand, bridge.Bridge)
+		if ok {
+			parseFDBStats(&ovsMetric, bridge.Bridge, fdbStatsOutput)
 		}
 	}
 
-	// Drop action too many resubmit
-	dropActionTooManyResubmitRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_too_many_resubmit.*total:\s*(\d+)`)
-	dropActionTooManyResubmitMatch := dropActionTooManyResubmitRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionTooManyResubmit = -1
-	if len(dropActionTooManyResubmitMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionTooManyResubmitMatch[1], 64)
-		if err == nil {
-			metrics.DropActionTooManyResubmit = v
-		}
-	}
+	if ofctl != nil {
+		for _, bridge := range ovsMetric.Bridges {
+			dumpTablesOutput, ok := ovsMetric.runCommand(ctx, ofctl, dumpTablesCommand, bridge.Bridge)
+			if ok {
+				parseOpenFlowTables(&ovsMetric, bridge.Bridge, dumpTablesOutput)
+			}
 
-	// Drop action stack too deep
-	dropActionStackTooDeepRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_stack_too_deep.*total:\s*(\d+)`)
-	dropActionStackTooDeepMatch := dropActionStackTooDeepRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionStackTooDeep = -1
-	if len(dropActionStackTooDeepMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionStackTooDeepMatch[1], 64)
-		if err == nil {
-			metrics.DropActionStackTooDeep = v
-		}
-	}
+			dumpPortsOutput, ok := ovsMetric.runCommand(ctx, ofctl, dumpPortsCommand, bridge.Bridge)
+			if ok {
+				parseOpenFlowPorts(&ovsMetric, bridge.Bridge, dumpPortsOutput)
+			}
 
-	// Drop action no recirculation context
-	dropActionNoRecirculationContextRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_no_recirculation_context.*total:\s*(\d+)`)
-	dropActionNoRecirculationContextMatch := dropActionNoRecirculationContextRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionNoRecirculationContext = -1
-	if len(dropActionNoRecirculationContextMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionNoRecirculationContextMatch[1], 64)
-		if err == nil {
-			metrics.DropActionNoRecirculationContext = v
+			meterStatsOutput, ok := ovsMetric.runOptionalCommand(ctx, ofctl, meterUnsupported, meterStatsCommand, bridge.Bridge)
+			if ok {
+				parseMeters(&ovsMetric, bridge.Bridge, meterStatsOutput)
+			}
 		}
 	}
 
-	// Drop action recirculation conflict
-	dropActionRecirculationConflictRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_recirculation_conflict.*total:\s*(\d+)`)
-	dropActionRecirculationConflictMatch := dropActionRecirculationConflictRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionRecirculationConflict = -1
-	if len(dropActionRecirculationConflictMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionRecirculationConflictMatch[1], 64)
-		if err == nil {
-			metrics.DropActionRecirculationConflict = v
+	if db != nil {
+		interfaceRows, ok := ovsMetric.runSelect(ctx, db, "Interface", interfaceColumns...)
+		if ok {
+			parseInterfaces(&ovsMetric, interfaceRows)
 		}
 	}
 
-	// Drop action too many MPLS labels
-	dropActionTooManyMplsLabelsRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_too_many_mpls_labels.*total:\s*(\d+)`)
-	dropActionTooManyMplsLabelsMatch := dropActionTooManyMplsLabelsRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionTooManyMplsLabels = -1
-	if len(dropActionTooManyMplsLabelsMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionTooManyMplsLabelsMatch[1], 64)
-		if err == nil {
-			metrics.DropActionTooManyMplsLabels = v
-		}
-	}
+	return &ovsMetric
+}
 
-	// Drop action invalid tunnel metadata
-	dropActionInvalidTunnelMetadataRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_invalid_tunnel_metadata.*total:\s*(\d+)`)
-	dropActionInvalidTunnelMetadataMatch := dropActionInvalidTunnelMetadataRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionInvalidTunnelMetadata = -1
-	if len(dropActionInvalidTunnelMetadataMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionInvalidTunnelMetadataMatch[1], 64)
-		if err == nil {
-			metrics.DropActionInvalidTunnelMetadata = v
-		}
-	}
+// runCommand runs an OVS command, recording its duration and error in
+// metrics. It returns false when the command failed.
+func (metrics *OvsMetric) runCommand(ctx context.Context, ctl appctl, command string, args ...string) (string, bool) {
+	start := time.Now()
+	output, err := ctl.run(ctx, command, args...)
+	return metrics.recordCommand(command, start, output, err)
+}
 
-	// Drop action unsupported packet type
-	dropActionUnsupportedPacketTypeRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_unsupported_packet_type.*total:\s*(\d+)`)
-	dropActionUnsupportedPacketTypeMatch := dropActionUnsupportedPacketTypeRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionUnsupportedPacketType = -1
-	if len(dropActionUnsupportedPacketTypeMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionUnsupportedPacketTypeMatch[1], 64)
-		if err == nil {
-			metrics.DropActionUnsupportedPacketType = v
+// runOptionalCommand runs an OVS command like runCommand, except that it
+// returns false without recording the command when its output or error
+// contains one of unsupported, the command not applying to this OVS.
+func (metrics *OvsMetric) runOptionalCommand(ctx context.Context, ctl appctl, unsupported []string, command string, args ...string) (string, bool) {
+	start := time.Now()
+	output, err := ctl.run(ctx, command, args...)
+	if err != nil {
+		for _, message := range unsupported {
+			if strings.Contains(output+err.Error(), message) {
+				return "", false
+			}
 		}
 	}
+	return metrics.recordCommand(command, start, output, err)
+}
 
-	// Drop action congestion
-	dropActionCongestionRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_congestion.*total:\s*(\d+)`)
-	dropActionCongestionMatch := dropActionCongestionRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionCongestion = -1
-	if len(dropActionCongestionMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionCongestionMatch[1], 64)
-		if err == nil {
-			metrics.DropActionCongestion = v
-		}
+// recordCommand records the duration and error of a command started at start.
+// It returns the command output, or false when the command failed.
+func (metrics *OvsMetric) recordCommand(command string, start time.Time, output string, err error) (string, bool) {
+	metrics.Commands = append(metrics.Commands, CommandResult{Command: command, Duration: time.Since(start), Err: err})
+	if err != nil {
+		fmt.Printf("Error running command %s: %v\n", command, err)
+		return "", false
 	}
+	return output, true
+}
 
-	// Drop action forwarding disabled
-	dropActionForwardingDisabledRegexp := regexp.MustCompile(`(?m)^[ \t]*drop_action_forwarding_disabled.*total:\s*(\d+)`)
-	dropActionForwardingDisabledMatch := dropActionForwardingDisabledRegexp.FindStringSubmatch(coverageStats)
-	metrics.DropActionForwardingDisabled = -1
-	if len(dropActionForwardingDisabledMatch) > 1 {
-		v, err := strconv.ParseFloat(dropActionForwardingDisabledMatch[1], 64)
-		if err == nil {
-			metrics.DropActionForwardingDisabled = v
-		}
+// runSelect selects rows of an OVSDB table, recording its duration and error
+// in metrics like runCommand.
+func (metrics *OvsMetric) runSelect(ctx context.Context, db ovsdb, table string, columns ...string) ([]ovsdbRow, bool) {
+	start := time.Now()
+	rows, err := db.selectRows(ctx, table, columns...)
+	metrics.Commands = append(metrics.Commands, CommandResult{Command: ovsdbCommand(table), Duration: time.Since(start), Err: err})
+	if err != nil {
+		fmt.Printf("Error selecting OVSDB table %s: %v\n", table, err)
+		return nil, false
 	}
+	return rows, true
+}
 
-	// Drop reasons new
-	// Netdev VXLAN TSO drops
-	netdevVxlanTsoDropsRegexp := regexp.MustCompile(`(?m)^[ \t]*netdev_vxlan_tso_drops.*total:\s*(\d+)`)
-	netdevVxlanTsoDropsMatch := netdevVxlanTsoDropsRegexp.FindStringSubmatch(coverageStats)
-	metrics.NetdevVxlanTsoDrops = -1
-	if len(netdevVxlanTsoDropsMatch) > 1 {
-		v, err := strconv.ParseFloat(netdevVxlanTsoDropsMatch[1], 64)
-		if err == nil {
-			metrics.NetdevVxlanTsoDrops = v
+// succeeded tells whether command ran without error, for at least one bridge
+// if it is run per bridge.
+func (metrics *OvsMetric) succeeded(command string) bool {
+	for _, result := range metrics.Commands {
+		if result.Command == command && result.Err == nil {
+			return true
 		}
 	}
+	return false
+}
 
-	// Netdev Geneve TSO drops
-	netdevGeneveTsoDropsRegexp := regexp.MustCompile(`(?m)^[ \t]*netdev_geneve_tso_drops.*total:\s*(\d+)`)
-	netdevGeneveTsoDropsMatch := netdevGeneveTsoDropsRegexp.FindStringSubmatch(coverageStats)
-	metrics.NetdevGeneveTsoDrops = -1
-	if len(netdevGeneveTsoDropsMatch) > 1 {
-		v, err := strconv.ParseFloat(netdevGeneveTsoDropsMatch[1], 64)
-		if err == nil {
-			metrics.NetdevGeneveTsoDrops = v
+// parseFailure records that the value of source in the output of command
+// couldn't be parsed, under the name of its registry metric.
+func (metrics *OvsMetric) parseFailure(command string, source string) {
+	for _, spec := range metricRegistry {
+		if spec.command == command && spec.source == source {
+			metrics.ParseFailures = append(metrics.ParseFailures, spec.name)
 		}
 	}
+}
 
-	// Netdev push header drops
-	netdevPushHeaderDropsRegexp := regexp.MustCompile(`(?m)^[ \t]*netdev_push_header_drops.*total:\s*(\d+)`)
-	netdevPushHeaderDropsMatch := netdevPushHeaderDropsRegexp.FindStringSubmatch(coverageStats)
-	metrics.NetdevPushHeaderDrops = -1
-	if len(netdevPushHeaderDropsMatch) > 1 {
-		v, err := strconv.ParseFloat(netdevPushHeaderDropsMatch[1], 64)
-		if err == nil {
-			metrics.NetdevPushHeaderDrops = v
-		}
-	}
+// sampleOptions bounds the samples of the registry metrics labelled by
+// coverage counter, conntrack zone or tunnel neighbor.
+type sampleOptions struct {
+	coverage coverageFilter
+	// ctMaxZones is the maximum number of zones exported, all if 0.
+	ctMaxZones int
+	// tunnelNeighbors enables ovsdp_tunnel_neighbor.
+	tunnelNeighbors bool
+}
 
-	// Netdev soft seg drops
-	netdevSoftSegDropsRegexp := regexp.MustCompile(`(?m)^[ \t]*netdev_soft_seg_drops.*total:\s*(\d+)`)
-	netdevSoftSegDropsMatch := netdevSoftSegDropsRegexp.FindStringSubmatch(coverageStats)
-	metrics.NetdevSoftSegDrops = -1
-	if len(netdevSoftSegDropsMatch) > 1 {
-		v, err := strconv.ParseFloat(netdevSoftSegDropsMatch[1], 64)
-		if err == nil {
-			metrics.NetdevSoftSegDrops = v
-		}
+// enabled returns whether the registry metric spec is exported.
+func (options sampleOptions) enabled(spec metricSpec) bool {
+	if spec.command == tunnelNeighborsCommand && spec.source == "neighbor" {
+		return options.tunnelNeighbors
 	}
+	return true
+}
 
-	// Datapath drop tunnel TSO recirc
-	datapathDropTunnelTsoRecircRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_tunnel_tso_recirc.*total:\s*(\d+)`)
-	datapathDropTunnelTsoRecircMatch := datapathDropTunnelTsoRecircRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropTunnelTsoRecirc = -1
-	if len(datapathDropTunnelTsoRecircMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropTunnelTsoRecircMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropTunnelTsoRecirc = v
-		}
-	}
+// samples returns the values of the registry metric spec found in metrics.
+func (metrics *OvsMetric) samples(spec metricSpec, options sampleOptions) []Sample {
+	var samples []Sample
+	switch spec.command {
+	case pmdStatsCommand:
+		for _, thread := range metrics.PMDThreads {
+			if v, ok := thread.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{thread.NumaID, thread.CoreID, thread.Thread}})
+			}
+		}
+	case coverageCommand:
+		samples = metrics.coverageSamples(spec, options.coverage)
+	case pmdRxqCommand:
+		samples = metrics.pmdRxqSamples(spec)
+	case pmdPerfCommand:
+		samples = metrics.pmdPerfSamples(spec)
+	case ctStatsCommand, ctNConnsCommand, ctMaxConnsCommand:
+		samples = metrics.conntrackSamples(spec)
+	case ctLimitsCommand:
+		samples = metrics.conntrackZoneSamples(spec, options.ctMaxZones)
+	case upcallCommand:
+		samples = metrics.upcallSamples(spec)
+	case dpctlShowCommand:
+		samples = metrics.datapathSamples(spec)
+	case dpifShowCommand:
+		samples = metrics.bridgeSamples(spec)
+	case offloadStatsCommand:
+		samples = metrics.offloadSamples(spec)
+	case dpdkMempoolCommand, dpdkMallocCommand:
+		samples = metrics.dpdkSamples(spec)
+	case bondCommand:
+		samples = metrics.bondSamples(spec)
+	case lacpCommand:
+		samples = metrics.lacpSamples(spec)
+	case bfdCommand, cfmCommand:
+		samples = metrics.bfdSamples(spec)
+	case tunnelPortsCommand, tunnelNeighborsCommand:
+		samples = metrics.tunnelSamples(spec)
+	case fdbStatsCommand:
+		samples = metrics.fdbSamples(spec)
+	case dumpTablesCommand, dumpPortsCommand, meterStatsCommand:
+		samples = metrics.openFlowSamples(spec)
+	case interfaceCommand:
+		samples = metrics.interfaceSamples(spec)
+	}
+	return samples
+}
 
-	// Datapath drop invalid bond
-	datapathDropInvalidBondRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_invalid_bond.*total:\s*(\d+)`)
-	datapathDropInvalidBondMatch := datapathDropInvalidBondRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropInvalidBond = -1
-	if len(datapathDropInvalidBondMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropInvalidBondMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropInvalidBond = v
-		}
+// boolValue converts a boolean to a metric value.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
 	}
+	return 0
+}
+
+// coverageSamples returns the total of the coverage counter named by source,
+// or the total or rates of every counter matching filter.
+func (metrics *OvsMetric) coverageSamples(spec metricSpec, filter coverageFilter) []Sample {
+	var samples []Sample
+	for _, counter := range metrics.Coverage {
+		switch {
+		case len(spec.labels) == 0:
+			if counter.Name == spec.source {
+				samples = append(samples, Sample{Value: counter.Total})
+			}
+		case !filter.matches(counter.Name):
+		case spec.source == "total":
+			samples = append(samples, Sample{Value: counter.Total, Labels: []string{counter.Name}})
+		case spec.source == "rate":
+			samples = append(samples,
+				Sample{Value: counter.Rate5s, Labels: []string{counter.Name, "5s"}},
+				Sample{Value: counter.Rate60s, Labels: []string{counter.Name, "60s"}},
+				Sample{Value: counter.Rate3600s, Labels: []string{counter.Name, "3600s"}},
+			)
+		}
+	}
+	return samples
+}
 
-	// Datapath drop HW miss recover
-	datapathDropHwMissRecoverRegexp := regexp.MustCompile(`(?m)^[ \t]*datapath_drop_hw_miss_recover.*total:\s*(\d+)`)
-	datapathDropHwMissRecoverMatch := datapathDropHwMissRecoverRegexp.FindStringSubmatch(coverageStats)
-	metrics.DatapathDropHwMissRecover = -1
-	if len(datapathDropHwMissRecoverMatch) > 1 {
-		v, err := strconv.ParseFloat(datapathDropHwMissRecoverMatch[1], 64)
-		if err == nil {
-			metrics.DatapathDropHwMissRecover = v
+func parseCoverage(metrics *OvsMetric, coverageStats string) {
+	counterRegexp := regexp.MustCompile(`(?m)^[ \t]*(\w+)\s+([\d.]+)/sec\s+([\d.]+)/sec\s+([\d.]+)/sec\s+total:\s*(\d+)`)
+	metrics.Coverage = nil
+	for _, match := range counterRegexp.FindAllStringSubmatch(coverageStats, -1) {
+		var values [4]float64
+		var err error
+		for i := range values {
+			if values[i], err = strconv.ParseFloat(match[i+2], 64); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			metrics.parseFailure(coverageCommand, "total")
+			metrics.parseFailure(coverageCommand, match[1])
+		} else {
+			metrics.Coverage = append(metrics.Coverage, CoverageCounter{
+				Name:      match[1],
+				Rate5s:    values[0],
+				Rate60s:   values[1],
+				Rate3600s: values[2],
+				Total:     values[3],
+			})
 		}
 	}
 }
 
-func parsePMDStats(metrics *OvsMetric, pmdStats string) {
+// pmdSection is the block of a dpif-netdev command output following a
+// "pmd thread numa_id X core_id Y:" or "main thread:" header.
+type pmdSection struct {
+	thread string
+	numaID string
+	coreID string
+	body   string
+}
 
-	missWithSuccessUpcallRegexp := regexp.MustCompile(`(?m)^[ \t]*miss\s+with\s+success\s+upcall:\s*(\d+)`)
-	missWithSuccessUpcallMatch := missWithSuccessUpcallRegexp.FindStringSubmatch(pmdStats)
-	metrics.MissWithSuccessUpcall = -1
-	if len(missWithSuccessUpcallMatch) > 1 {
-		v, err := strconv.ParseFloat(missWithSuccessUpcallMatch[1], 64)
-		if err == nil {
-			metrics.MissWithSuccessUpcall = v
-		}
-	}
+func splitPMDSections(output string) []pmdSection {
+	headerRegexp := regexp.MustCompile(`(?m)^[ \t]*(?:pmd thread numa_id (\d+) core_id (\d+)|main thread):`)
+	headers := headerRegexp.FindAllStringSubmatchIndex(output, -1)
 
-	missWithFailedUpcallRegexp := regexp.MustCompile(`(?m)^[ \t]*miss\s+with\s+failed\s+upcall:\s*(\d+)`)
-	missWithFailedUpcallMatch := missWithFailedUpcallRegexp.FindStringSubmatch(pmdStats)
-	metrics.MissWithFailedUpcall = -1
-	if len(missWithFailedUpcallMatch) > 1 {
-		v, err := strconv.ParseFloat(missWithFailedUpcallMatch[1], 64)
-		if err == nil {
-			metrics.MissWithFailedUpcall = v
+	var sections []pmdSection
+	for i, header := range headers {
+		end := len(output)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
 		}
-	}
 
-	processingCyclesRegexp := regexp.MustCompile(`(?m)^[ \t]*processing cycles:.*\((\d{1,3}(?:\.\d+)?)%\)`)
-	processingCyclesMatch := processingCyclesRegexp.FindStringSubmatch(pmdStats)
-	metrics.ProcessingCycles = -1
-	if len(processingCyclesMatch) > 1 {
-		v, err := strconv.ParseFloat(processingCyclesMatch[1], 64)
-		if err == nil {
-			metrics.ProcessingCycles = v
+		section := pmdSection{thread: "main", body: output[header[1]:end]}
+		if header[2] >= 0 {
+			section.thread = "pmd"
+			section.numaID = output[header[2]:header[3]]
+			section.coreID = output[header[4]:header[5]]
 		}
+		sections = append(sections, section)
 	}
+	return sections
+}
 
-	idleCyclesRegexp := regexp.MustCompile(`(?m)^[ \t]*idle cycles:.*\((\d{1,3}(?:\.\d+)?)%\)`)
-	idleCyclesMatch := idleCyclesRegexp.FindStringSubmatch(pmdStats)
-	metrics.IdleCycles = -1
-	if len(idleCyclesMatch) > 1 {
-		v, err := strconv.ParseFloat(idleCyclesMatch[1], 64)
-		if err == nil {
-			metrics.IdleCycles = v
-		}
+func parsePMDStats(metrics *OvsMetric, pmdStats string) {
+	metrics.PMDThreads = nil
+	for _, section := range splitPMDSections(pmdStats) {
+		thread := PMDThreadMetric{Thread: section.thread, NumaID: section.numaID, CoreID: section.coreID}
+		parsePMDThreadStats(metrics, &thread, section.body)
+		metrics.PMDThreads = append(metrics.PMDThreads, thread)
 	}
+}
 
-	avgSubtableLookupsMegaflowRegexp := regexp.MustCompile(`(?m)^[ \t]*avg\.\s+subtable\s+lookups\s+per\s+megaflow\s+hit:[ \t]*(\d+(\.\d+)?)`)
-	avgSubtableLookupsMegaflowMatch := avgSubtableLookupsMegaflowRegexp.FindStringSubmatch(pmdStats)
-	metrics.AvgSubtableLookupsMegaflow = -1
-	if len(avgSubtableLookupsMegaflowMatch) > 1 {
-		v, err := strconv.ParseFloat(avgSubtableLookupsMegaflowMatch[1], 64)
-		if err == nil {
-			metrics.AvgSubtableLookupsMegaflow = v
+func parsePMDThreadStats(metrics *OvsMetric, thread *PMDThreadMetric, pmdStats string) {
+	// e.g. "idle cycles: 731072761249336 (99.80%)"
+	lineRegexp := regexp.MustCompile(`(?m)^[ \t]*(\w[\w. ]*?):[ \t]*(\d+(?:\.\d+)?)(?:.*\((\d+(?:\.\d+)?)%\))?`)
+
+	thread.Values = make(map[string]float64)
+	for _, match := range lineRegexp.FindAllStringSubmatch(pmdStats, -1) {
+		v, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			metrics.parseFailure(pmdStatsCommand, match[1])
+			continue
+		}
+		thread.Values[match[1]] = v
+
+		if match[3] != "" {
+			v, err := strconv.ParseFloat(match[3], 64)
+			if err != nil {
+				metrics.parseFailure(pmdStatsCommand, match[1]+" %")
+				continue
+			}
+			thread.Values[match[1]+" %"] = v
 		}
 	}
 }
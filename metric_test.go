@@ -1,19 +1,101 @@
 package main
 
 import (
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-func Test_parseCoverageDoca(t *testing.T) {
+func Test_metricRegistry(t *testing.T) {
+	names := make(map[string]bool)
+	sources := make(map[string]bool)
+	for _, spec := range metricRegistry {
+		if names[spec.name] {
+			t.Errorf("Metric %s is registered twice", spec.name)
+		}
+		names[spec.name] = true
+
+		source := spec.command + " " + spec.source
+		if sources[source] {
+			t.Errorf("Source %q is registered twice", source)
+		}
+		sources[source] = true
+	}
+
+	// The pedantic registry rejects invalid and duplicate descriptors.
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(newOvsDPCollector(fakeAppctl{}, nil, nil, 0, coverageFilter{}, 0, nil, false)); err != nil {
+		t.Errorf("Collector can't be registered: %v", err)
+	}
+}
+
+func Test_pmdMetrics(t *testing.T) {
+	var ovsMetric OvsMetric
+	parsePMDStats(&ovsMetric, `pmd thread numa_id 0 core_id 11:
+  packets received: 89813835
+  packet recirculations: 25377014
+  avg. datapath passes per packet: 1.28
+  phwol hits: 4596
+  mfex opt hits: 0
+  simple match hits: 22
+  emc hits: 3392099
+  smc hits: 0
+  megaflow hits: 78498765
+  avg. subtable lookups per megaflow hit: 5.38
+  miss with success upcall: 33284747
+  miss with failed upcall: 10620
+  avg. packets per output batch: 1.05
+  idle cycles: 731072761249336 (99.80%)
+  processing cycles: 1492654477083 (0.20%)
+  avg cycles per packet: 8156487.42 (732565415726419/89813835)
+  avg processing cycles per packet: 16619.43 (1492654477083/89813835)`)
+
+	metrics := make(map[string][]Sample)
+	for _, spec := range metricRegistry {
+		if spec.command == pmdStatsCommand {
+			metrics[spec.name] = ovsMetric.samples(spec, sampleOptions{})
+		}
+	}
+
+	labels := []string{"0", "11", "pmd"}
+	expected := map[string][]Sample{
+		"ovsdp_packets_received":                 {{Value: 89813835, Labels: labels}},
+		"ovsdp_packet_recirculations":            {{Value: 25377014, Labels: labels}},
+		"ovsdp_avg_datapath_passes_per_packet":   {{Value: 1.28, Labels: labels}},
+		"ovsdp_phwol_hits":                       {{Value: 4596, Labels: labels}},
+		"ovsdp_mfex_opt_hits":                    {{Value: 0, Labels: labels}},
+		"ovsdp_simple_match_hits":                {{Value: 22, Labels: labels}},
+		"ovsdp_emc_hits":                         {{Value: 3392099, Labels: labels}},
+		"ovsdp_smc_hits":                         {{Value: 0, Labels: labels}},
+		"ovsdp_megaflow_hits":                    {{Value: 78498765, Labels: labels}},
+		"ovsdp_avg_subtable_lookups_megaflow":    {{Value: 5.38, Labels: labels}},
+		"ovsdp_miss_with_success_upcall":         {{Value: 33284747, Labels: labels}},
+		"ovsdp_miss_with_failed_upcall":          {{Value: 10620, Labels: labels}},
+		"ovsdp_avg_packets_per_output_batch":     {{Value: 1.05, Labels: labels}},
+		"ovsdp_pmd_idle_cycles_total":            {{Value: 731072761249336, Labels: labels}},
+		"ovsdp_idle_cycles":                      {{Value: 99.80, Labels: labels}},
+		"ovsdp_pmd_processing_cycles_total":      {{Value: 1492654477083, Labels: labels}},
+		"ovsdp_processing_cycles":                {{Value: 0.20, Labels: labels}},
+		"ovsdp_avg_cycles_per_packet":            {{Value: 8156487.42, Labels: labels}},
+		"ovsdp_avg_processing_cycles_per_packet": {{Value: 16619.43, Labels: labels}},
+	}
+	diff := cmp.Diff(metrics, expected)
+	if diff != "" {
+		t.Errorf("Metrics are different:\n%s", diff)
+	}
+}
+
+func Test_coverageMetrics(t *testing.T) {
 	tests := []struct {
-		name   string
-		output string
-		metric OvsMetric
+		name    string
+		output  string
+		metrics map[string]float64
 	}{
 		{
-			name: "output1",
+			name: "doca",
 			output: `ovs_doca_no_mark  0.0/sec     0.000/sec        0.0000/sec   total: 5
 ovs_doca_invalid_classify_port  0.0/sec     0.000/sec        0.0000/sec   total: 8
 doca_queue_empty  0.0/sec     0.000/sec        0.0000/sec   total: 12
@@ -21,42 +103,19 @@ doca_queue_none_processed  0.0/sec     0.000/sec        0.0000/sec   total: 15
 doca_resize_block  0.0/sec     0.000/sec        0.0000/sec   total: 20
 doca_pipe_resize  0.0/sec     0.000/sec        0.0000/sec   total: 25
 doca_pipe_resize_over_10_ms  0.0/sec     0.000/sec        0.0000/sec   total: 30`,
-			metric: OvsMetric{
+			metrics: map[string]float64{
 				// DOCA
-				OvsDocaNoMark:              5,
-				OvsDocaInvalidClassifyPort: 8,
-				DocaQueueEmpty:             12,
-				DocaQueueNoneProcessed:     15,
-				DocaResizeBlock:            20,
-				DocaPipeResize:             25,
-				DocaPipeResizeOver10Ms:     30,
+				"ovsdp_ovs_doca_no_mark":               5,
+				"ovsdp_ovs_doca_invalid_classify_port": 8,
+				"ovsdp_doca_queue_empty":               12,
+				"ovsdp_doca_queue_none_processed":      15,
+				"ovsdp_doca_resize_block":              20,
+				"ovsdp_doca_pipe_resize":               25,
+				"ovsdp_doca_pipe_resize_over_10_ms":    30,
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			var ovsMetric OvsMetric
-			parseCoverageDoca(&ovsMetric, tt.output)
-
-			diff := cmp.Diff(ovsMetric, tt.metric)
-			// If there's a difference, `cmp.Diff` will return a string representation of the diff
-			if diff != "" {
-				t.Errorf("Structs are different:\n%s", diff)
-			}
-		})
-	}
-}
-
-func Test_parseCoverageDropReasons(t *testing.T) {
-
-	tests := []struct {
-		name   string
-		output string
-		metric OvsMetric
-	}{
 		{
-			name: "output1",
+			name: "drop reasons",
 			output: `
 datapath_drop_upcall_error   0.0/sec     0.000/sec        0.0000/sec   total: 5
 datapath_drop_lock_error   0.0/sec     0.000/sec        0.0000/sec   total: 6
@@ -89,40 +148,46 @@ netdev_soft_seg_drops   0.0/sec     0.000/sec        0.0000/sec   total: 32
 datapath_drop_tunnel_tso_recirc   0.0/sec     0.000/sec        0.0000/sec   total: 33
 datapath_drop_invalid_bond   0.0/sec     0.000/sec        0.0000/sec   total: 34
 datapath_drop_hw_miss_recover   0.0/sec     0.000/sec        0.0000/sec   total: 35`,
-			metric: OvsMetric{
+			metrics: map[string]float64{
 				// Drop reasons
-				UpcallDrops:                      5,
-				UpcallDropsLockError:             6,
-				RxDropsInvalidPacket:             7,
-				DatapathDropMeter:                8,
-				DatapathDropUserspaceActionError: 9,
-				DatapathDropTunnelPushError:      10,
-				DatapathDropTunnelPopError:       11,
-				DatapathDropRecircError:          12,
-				DatapathDropInvalidPort:          13,
-				DatapathDropInvalidTnlPort:       14,
-				DatapathDropSampleError:          15,
-				DatapathDropNshDecapError:        16,
-				DropActionOfPipeline:             17,
-				DropActionBridgeNotFound:         18,
-				DropActionRecursionTooDeep:       19,
-				DropActionTooManyResubmit:        20,
-				DropActionStackTooDeep:           21,
-				DropActionNoRecirculationContext: 22,
-				DropActionRecirculationConflict:  23,
-				DropActionTooManyMplsLabels:      24,
-				DropActionInvalidTunnelMetadata:  25,
-				DropActionUnsupportedPacketType:  26,
-				DropActionCongestion:             27,
-				DropActionForwardingDisabled:     28,
-				// Drop reasons new
-				NetdevVxlanTsoDrops:         29,
-				NetdevGeneveTsoDrops:        30,
-				NetdevPushHeaderDrops:       31,
-				NetdevSoftSegDrops:          32,
-				DatapathDropTunnelTsoRecirc: 33,
-				DatapathDropInvalidBond:     34,
-				DatapathDropHwMissRecover:   35,
+				"ovsdp_datapath_drop_upcall_error":           5,
+				"ovsdp_datapath_drop_lock_error":             6,
+				"ovsdp_datapath_drop_rx_invalid_packet":      7,
+				"ovsdp_datapath_drop_meter":                  8,
+				"ovsdp_datapath_drop_userspace_action_error": 9,
+				"ovsdp_datapath_drop_tunnel_push_error":      10,
+				"ovsdp_datapath_drop_tunnel_pop_error":       11,
+				"ovsdp_datapath_drop_recirc_error":           12,
+				"ovsdp_datapath_drop_invalid_port":           13,
+				"ovsdp_datapath_drop_invalid_tnl_port":       14,
+				"ovsdp_datapath_drop_sample_error":           15,
+				"ovsdp_datapath_drop_nsh_decap_error":        16,
+				"ovsdp_drop_action_of_pipeline":              17,
+				"ovsdp_drop_action_bridge_not_found":         18,
+				"ovsdp_drop_action_recursion_too_deep":       19,
+				"ovsdp_drop_action_too_many_resubmit":        20,
+				"ovsdp_drop_action_stack_too_deep":           21,
+				"ovsdp_drop_action_no_recirculation_context": 22,
+				"ovsdp_drop_action_recirculation_conflict":   23,
+				"ovsdp_drop_action_too_many_mpls_labels":     24,
+				"ovsdp_drop_action_invalid_tunnel_metadata":  25,
+				"ovsdp_drop_action_unsupported_packet_type":  26,
+				"ovsdp_drop_action_congestion":               27,
+				"ovsdp_drop_action_forwarding_disabled":      28,
+				"ovsdp_netdev_vxlan_tso_drops":               29,
+				"ovsdp_netdev_geneve_tso_drops":              30,
+				"ovsdp_netdev_push_header_drops":             31,
+				"ovsdp_netdev_soft_seg_drops":                32,
+				"ovsdp_datapath_drop_tunnel_tso_recirc":      33,
+				"ovsdp_datapath_drop_invalid_bond":           34,
+				"ovsdp_datapath_drop_hw_miss_recover":        35,
+			},
+		},
+		{
+			name:   "missing counters",
+			output: `ovs_doca_no_mark  0.0/sec     0.000/sec        0.0000/sec   total: 0`,
+			metrics: map[string]float64{
+				"ovsdp_ovs_doca_no_mark": 0,
 			},
 		},
 	}
@@ -130,16 +195,58 @@ datapath_drop_hw_miss_recover   0.0/sec     0.000/sec        0.0000/sec   total:
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var ovsMetric OvsMetric
-			parseCoverageDropReasons(&ovsMetric, tt.output)
+			parseCoverage(&ovsMetric, tt.output)
 
-			diff := cmp.Diff(ovsMetric, tt.metric)
+			metrics := make(map[string]float64)
+			for _, spec := range metricRegistry {
+				if spec.command != coverageCommand || len(spec.labels) > 0 {
+					continue
+				}
+				for _, sample := range ovsMetric.samples(spec, sampleOptions{}) {
+					metrics[spec.name] = sample.Value
+				}
+			}
+
+			diff := cmp.Diff(metrics, tt.metrics)
+			// If there's a difference, `cmp.Diff` will return a string representation of the diff
 			if diff != "" {
-				t.Errorf("Structs are different:\n%s", diff)
+				t.Errorf("Metrics are different:\n%s", diff)
 			}
 		})
 	}
 }
 
+func Test_coverageSamples(t *testing.T) {
+	ovsMetric := OvsMetric{
+		Coverage: []CoverageCounter{
+			{Name: "netlink_sent", Rate5s: 5.4, Rate60s: 3.217, Rate3600s: 2.9875, Total: 4112763},
+			{Name: "dpif_flow_put", Rate5s: 0, Rate60s: 0.05, Rate3600s: 0.0417, Total: 1337},
+			{Name: "datapath_drop_meter", Rate5s: 0, Rate60s: 0, Rate3600s: 0, Total: 0},
+		},
+	}
+	options := sampleOptions{
+		coverage: coverageFilter{allow: regexp.MustCompile(`^(?:netlink_.*|dpif_.*)$`), deny: regexp.MustCompile(`^(?:dpif_flow_put)$`)},
+	}
+
+	total := ovsMetric.samples(metricSpec{command: coverageCommand, source: "total", labels: coverageLabels}, options)
+	expected := []Sample{{Value: 4112763, Labels: []string{"netlink_sent"}}}
+	diff := cmp.Diff(total, expected)
+	if diff != "" {
+		t.Errorf("Samples are different:\n%s", diff)
+	}
+
+	rate := ovsMetric.samples(metricSpec{command: coverageCommand, source: "rate", labels: coverageRateLabels}, options)
+	expected = []Sample{
+		{Value: 5.4, Labels: []string{"netlink_sent", "5s"}},
+		{Value: 3.217, Labels: []string{"netlink_sent", "60s"}},
+		{Value: 2.9875, Labels: []string{"netlink_sent", "3600s"}},
+	}
+	diff = cmp.Diff(rate, expected)
+	if diff != "" {
+		t.Errorf("Samples are different:\n%s", diff)
+	}
+}
+
 func Test_metricParsePMDStats(t *testing.T) {
 
 	tests := []struct {
@@ -183,11 +290,53 @@ miss with success upcall: 2
 miss with failed upcall: 0
 avg. packets per output batch: 0.00`,
 			metric: OvsMetric{
-				MissWithFailedUpcall:       10620,
-				IdleCycles:                 99.80,
-				ProcessingCycles:           0.20,
-				MissWithSuccessUpcall:      33284747,
-				AvgSubtableLookupsMegaflow: 5.38,
+				// PMD stats
+				PMDThreads: []PMDThreadMetric{
+					{
+						Thread: "pmd",
+						NumaID: "0",
+						CoreID: "11",
+						Values: map[string]float64{
+							"packets received":                       89813835,
+							"packet recirculations":                  25377014,
+							"avg. datapath passes per packet":        1.28,
+							"phwol hits":                             4596,
+							"mfex opt hits":                          0,
+							"simple match hits":                      22,
+							"emc hits":                               3392099,
+							"smc hits":                               0,
+							"megaflow hits":                          78498765,
+							"avg. subtable lookups per megaflow hit": 5.38,
+							"miss with success upcall":               33284747,
+							"miss with failed upcall":                10620,
+							"avg. packets per output batch":          1.05,
+							"idle cycles":                            731072761249336,
+							"idle cycles %":                          99.80,
+							"processing cycles":                      1492654477083,
+							"processing cycles %":                    0.20,
+							"avg cycles per packet":                  8156487.42,
+							"avg processing cycles per packet":       16619.43,
+						},
+					},
+					{
+						Thread: "main",
+						Values: map[string]float64{
+							"packets received":                       4,
+							"packet recirculations":                  0,
+							"avg. datapath passes per packet":        1.00,
+							"phwol hits":                             0,
+							"mfex opt hits":                          0,
+							"simple match hits":                      2,
+							"emc hits":                               0,
+							"smc hits":                               0,
+							"megaflow hits":                          0,
+							"avg. subtable lookups per megaflow hit": 0.00,
+							"miss with success upcall":               2,
+							"miss with failed upcall":                0,
+							"avg. packets per output batch":          0.00,
+						},
+					},
+				},
 			},
 		},
 		{
@@ -227,11 +376,105 @@ miss with failed upcall: 0
 avg. packets per output batch: 0.00`,
 			metric: OvsMetric{
 				// PMD stats
-				MissWithFailedUpcall:       0,
-				IdleCycles:                 100,
-				ProcessingCycles:           0,
-				MissWithSuccessUpcall:      1047,
-				AvgSubtableLookupsMegaflow: 1.2,
+				PMDThreads: []PMDThreadMetric{
+					{
+						Thread: "pmd",
+						NumaID: "0",
+						CoreID: "11",
+						Values: map[string]float64{
+							"packets received":                       7828,
+							"packet recirculations":                  0,
+							"avg. datapath passes per packet":        1.00,
+							"phwol hits":                             0,
+							"mfex opt hits":                          0,
+							"simple match hits":                      6,
+							"emc hits":                               6662,
+							"smc hits":                               0,
+							"megaflow hits":                          81,
+							"avg. subtable lookups per megaflow hit": 1.20,
+							"miss with success upcall":               1047,
+							"miss with failed upcall":                0,
+							"avg. packets per output batch":          1.00,
+							"idle cycles":                            33408495423437,
+							"idle cycles %":                          100.00,
+							"processing cycles":                      468243547,
+							"processing cycles %":                    0.00,
+							"avg cycles per packet":                  4267879875.70,
+							"avg processing cycles per packet":       59816.50,
+						},
+					},
+					{
+						Thread: "main",
+						Values: map[string]float64{
+							"packets received":                       3378,
+							"packet recirculations":                  0,
+							"avg. datapath passes per packet":        1.00,
+							"phwol hits":                             0,
+							"mfex opt hits":                          0,
+							"simple match hits":                      3373,
+							"emc hits":                               0,
+							"smc hits":                               0,
+							"megaflow hits":                          2,
+							"avg. subtable lookups per megaflow hit": 1.50,
+							"miss with success upcall":               3,
+							"miss with failed upcall":                0,
+							"avg. packets per output batch":          0.00,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple pmd threads",
+			output: `
+pmd thread numa_id 0 core_id 2:
+  packets received: 100
+  miss with success upcall: 10
+  miss with failed upcall: 1
+  avg. subtable lookups per megaflow hit: 1.00
+  idle cycles: 900 (90.00%)
+  processing cycles: 100 (10.00%)
+pmd thread numa_id 1 core_id 34:
+  packets received: 200
+  miss with success upcall: 20
+  miss with failed upcall: 2
+  avg. subtable lookups per megaflow hit: 2.50
+  idle cycles: 250 (25.00%)
+  processing cycles: 750 (75.00%)`,
+			metric: OvsMetric{
+				// PMD stats
+				PMDThreads: []PMDThreadMetric{
+					{
+						Thread: "pmd",
+						NumaID: "0",
+						CoreID: "2",
+						Values: map[string]float64{
+							"packets received":                       100,
+							"miss with success upcall":               10,
+							"miss with failed upcall":                1,
+							"avg. subtable lookups per megaflow hit": 1.00,
+							"idle cycles":                            900,
+							"idle cycles %":                          90.00,
+							"processing cycles":                      100,
+							"processing cycles %":                    10.00,
+						},
+					},
+					{
+						Thread: "pmd",
+						NumaID: "1",
+						CoreID: "34",
+						Values: map[string]float64{
+							"packets received":                       200,
+							"miss with success upcall":               20,
+							"miss with failed upcall":                2,
+							"avg. subtable lookups per megaflow hit": 2.50,
+							"idle cycles":                            250,
+							"idle cycles %":                          25.00,
+							"processing cycles":                      750,
+							"processing cycles %":                    75.00,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -250,3 +493,50 @@ avg. packets per output batch: 0.00`,
 		})
 	}
 }
+
+func Test_parseCoverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `Event coverage, avg rate over last: 5 seconds, last minute, last hour,  hash=3a1c4e21:
+netlink_sent               5.4/sec     3.217/sec        2.9875/sec   total: 4112763
+dpif_flow_put              0.0/sec     0.050/sec        0.0417/sec   total: 1337
+upcall_ukey_replace        0.0/sec     0.000/sec        0.0000/sec   total: 12
+rev_flow_table             0.0/sec     0.000/sec        0.0003/sec   total: 4
+datapath_drop_meter        0.0/sec     0.000/sec        0.0000/sec   total: 0
+123 events never hit`,
+			metric: OvsMetric{
+				Coverage: []CoverageCounter{
+					{Name: "netlink_sent", Rate5s: 5.4, Rate60s: 3.217, Rate3600s: 2.9875, Total: 4112763},
+					{Name: "dpif_flow_put", Rate5s: 0, Rate60s: 0.05, Rate3600s: 0.0417, Total: 1337},
+					{Name: "upcall_ukey_replace", Rate5s: 0, Rate60s: 0, Rate3600s: 0, Total: 12},
+					{Name: "rev_flow_table", Rate5s: 0, Rate60s: 0, Rate3600s: 0.0003, Total: 4},
+					{Name: "datapath_drop_meter", Rate5s: 0, Rate60s: 0, Rate3600s: 0, Total: 0},
+				},
+			},
+		},
+		{
+			name:   "out of range",
+			output: "datapath_drop_meter   0.0/sec     0.000/sec        0.0000/sec   total: 1" + strings.Repeat("0", 400),
+			metric: OvsMetric{
+				ParseFailures: []string{"ovsdp_coverage_total", "ovsdp_datapath_drop_meter"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseCoverage(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// OffloadMetric holds the hardware offload stats from
+// dpctl/offload-stats-show.
+type OffloadMetric struct {
+	// Values are keyed by the text before the colon of the "Total" lines,
+	// e.g. "Total Inserted offloads".
+	Values  map[string]float64
+	Threads []OffloadThreadMetric
+}
+
+// OffloadThreadMetric holds the stats of an offload thread.
+type OffloadThreadMetric struct {
+	ID string
+	// Values are keyed by the text before the colon without the thread id,
+	// e.g. "Enqueued offloads" or "Cumulative Average latency (us)".
+	Values map[string]float64
+}
+
+// offloadUnsupported are the errors of offload-stats-show when the datapath
+// doesn't offload flows to hardware, i.e. the kernel datapath or hw-offload
+// isn't enabled.
+var offloadUnsupported = []string{
+	"retrieving offload statistics (Operation not supported)",
+	"retrieving offload statistics (Invalid argument)",
+}
+
+// offloadSamples returns the values of an offload-stats-show registry
+// metric.
+func (metrics *OvsMetric) offloadSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	if v, ok := metrics.Offload.Values[spec.source]; ok {
+		samples = append(samples, Sample{Value: v})
+	}
+	for _, thread := range metrics.Offload.Threads {
+		if v, ok := thread.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{thread.ID}})
+		}
+	}
+	return samples
+}
+
+func parseOffload(metrics *OvsMetric, offloadStats string) {
+	// e.g. "  [  0]   Cumulative Average latency (us):    123" or
+	// "  Total                 Inserted offloads:      3"
+	lineRegexp := regexp.MustCompile(`(?m)^[ \t]*(?:\[\s*(\d+)\]|(Total))\s+(\S[^:]*?):\s*(\d+(?:\.\d+)?)`)
+
+	metrics.Offload = OffloadMetric{Values: make(map[string]float64)}
+	for _, match := range lineRegexp.FindAllStringSubmatch(offloadStats, -1) {
+		source := match[3]
+		if match[2] != "" {
+			source = "Total " + source
+		}
+		v, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			metrics.parseFailure(offloadStatsCommand, source)
+			continue
+		}
+		if match[2] != "" {
+			metrics.Offload.Values[source] = v
+			continue
+		}
+
+		threads := metrics.Offload.Threads
+		if len(threads) == 0 || threads[len(threads)-1].ID != match[1] {
+			metrics.Offload.Threads = append(threads, OffloadThreadMetric{ID: match[1], Values: make(map[string]float64)})
+		}
+		metrics.Offload.Threads[len(metrics.Offload.Threads)-1].Values[source] = v
+	}
+}
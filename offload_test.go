@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseOffload(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `HW Offload stats:
+   [  0]                 Enqueued offloads:      2
+   [  0]                 Inserted offloads:    120
+   [  0]   Cumulative Average latency (us):     85
+   [  0]    Cumulative Latency stddev (us):     30
+   [  0]  Exponential Average latency (us):     70
+   [  0]   Exponential Latency stddev (us):     12
+   [  1]                 Enqueued offloads:      0
+   [  1]                 Inserted offloads:     80
+   Total                 Enqueued offloads:      2
+   Total                 Inserted offloads:    200
+   Total   Cumulative Average latency (us):     80
+`,
+			metric: OvsMetric{
+				Offload: OffloadMetric{
+					Values: map[string]float64{
+						"Total Enqueued offloads":               2,
+						"Total Inserted offloads":               200,
+						"Total Cumulative Average latency (us)": 80,
+					},
+					Threads: []OffloadThreadMetric{
+						{
+							ID: "0",
+							Values: map[string]float64{
+								"Enqueued offloads":                2,
+								"Inserted offloads":                120,
+								"Cumulative Average latency (us)":  85,
+								"Cumulative Latency stddev (us)":   30,
+								"Exponential Average latency (us)": 70,
+								"Exponential Latency stddev (us)":  12,
+							},
+						},
+						{
+							ID:     "1",
+							Values: map[string]float64{"Enqueued offloads": 0, "Inserted offloads": 80},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseOffload(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+// offloadlessAppctl fails offload-stats-show like ovs-vswitchd does without
+// hardware offload.
+type offloadlessAppctl struct {
+	message string
+}
+
+func (ctl offloadlessAppctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	return "ovs-vswitchd: retrieving offload statistics (" + ctl.message + ")\novs-appctl: ovs-vswitchd: server returned an error\n", errors.New("exit status 2")
+}
+
+func Test_runOptionalCommandOffload(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctl      appctl
+		recorded bool
+	}{
+		{name: "kernel datapath", ctl: offloadlessAppctl{message: "Operation not supported"}},
+		{name: "hw-offload disabled", ctl: offloadlessAppctl{message: "Invalid argument"}},
+		{name: "other error", ctl: offloadlessAppctl{message: "Cannot allocate memory"}, recorded: true},
+		{name: "unknown command", ctl: fakeAppctl{}, recorded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			if _, ok := ovsMetric.runOptionalCommand(context.Background(), tt.ctl, offloadUnsupported, offloadStatsCommand); ok {
+				t.Error("Expected the command to fail")
+			}
+			if recorded := len(ovsMetric.Commands) == 1; recorded != tt.recorded {
+				t.Errorf("Expected the failed command to be recorded %v, got %v", tt.recorded, ovsMetric.Commands)
+			}
+		})
+	}
+}
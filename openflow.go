@@ -0,0 +1,202 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// OpenFlowTableMetric holds the stats of an OpenFlow table from
+// ovs-ofctl dump-tables.
+type OpenFlowTableMetric struct {
+	Bridge string
+	Table  string
+	// Values hold "active" flows and the "lookup" and "matched" totals.
+	Values map[string]float64
+}
+
+// OpenFlowPortMetric holds the counters of an OpenFlow port from ovs-ofctl
+// dump-ports.
+type OpenFlowPortMetric struct {
+	Bridge string
+	Port   string
+	// Values are keyed by direction and counter name, e.g. "rx pkts" or
+	// "tx drop". Counters the port doesn't support are missing.
+	Values map[string]float64
+}
+
+// MeterMetric holds the stats of an OpenFlow meter from ovs-ofctl
+// meter-stats.
+type MeterMetric struct {
+	Bridge  string
+	MeterID string
+	// Values hold "flow_count", "packet_in_count" and "byte_in_count".
+	Values map[string]float64
+	Bands  []MeterBandMetric
+}
+
+// MeterBandMetric holds the stats of a meter band, counting the packets and
+// bytes it applied to, i.e. dropped for a drop band.
+type MeterBandMetric struct {
+	Band string
+	// Values hold "packet_count" and "byte_count".
+	Values map[string]float64
+}
+
+// meterUnsupported is the error of meter-stats on a bridge that doesn't
+// allow OpenFlow 1.3, which meters need, e.g. "ovs-ofctl: switch does not
+// support any of the usable flow formats (OXM-OpenFlow13,...)".
+var meterUnsupported = []string{"usable flow formats"}
+
+// openFlowSamples returns the values of an ovs-ofctl registry metric.
+func (metrics *OvsMetric) openFlowSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	switch spec.command {
+	case dumpTablesCommand:
+		for _, table := range metrics.OpenFlowTables {
+			if v, ok := table.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{table.Bridge, table.Table}})
+			}
+		}
+	case dumpPortsCommand:
+		for _, port := range metrics.OpenFlowPorts {
+			if v, ok := port.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{port.Bridge, port.Port}})
+			}
+		}
+	case meterStatsCommand:
+		for _, meter := range metrics.Meters {
+			if v, ok := meter.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{meter.Bridge, meter.MeterID}})
+			}
+			for _, band := range meter.Bands {
+				if v, ok := band.Values[spec.source]; ok {
+					samples = append(samples, Sample{Value: v, Labels: []string{meter.Bridge, meter.MeterID, band.Band}})
+				}
+			}
+		}
+	}
+	return samples
+}
+
+func parseOpenFlowTables(metrics *OvsMetric, bridge string, dumpTables string) {
+	// e.g. "  table 0:" or "  table 0 ("classifier"):"
+	tableRegexp := regexp.MustCompile(`^[ \t]*table (\d+)(?: \("[^"]*"\))?:`)
+	// e.g. "  tables 2...253: ditto", the tables having the stats of the
+	// previous one
+	dittoRegexp := regexp.MustCompile(`^[ \t]*tables (\d+)\.\.\.(\d+): ditto`)
+	// e.g. "    active=3, lookup=100, matched=90"
+	valueRegexp := regexp.MustCompile(`(active|lookup|matched)=(\d+)`)
+
+	for _, line := range strings.Split(dumpTables, "\n") {
+		if match := dittoRegexp.FindStringSubmatch(line); match != nil {
+			tables := metrics.OpenFlowTables
+			if len(tables) == 0 || tables[len(tables)-1].Bridge != bridge {
+				continue
+			}
+			previous := tables[len(tables)-1]
+			first, _ := strconv.Atoi(match[1])
+			last, _ := strconv.Atoi(match[2])
+			for id := first; id <= last; id++ {
+				table := OpenFlowTableMetric{Bridge: bridge, Table: strconv.Itoa(id), Values: make(map[string]float64, len(previous.Values))}
+				for source, v := range previous.Values {
+					table.Values[source] = v
+				}
+				metrics.OpenFlowTables = append(metrics.OpenFlowTables, table)
+			}
+			continue
+		}
+		if match := tableRegexp.FindStringSubmatch(line); match != nil {
+			metrics.OpenFlowTables = append(metrics.OpenFlowTables, OpenFlowTableMetric{Bridge: bridge, Table: match[1], Values: make(map[string]float64)})
+			// The stats may follow on the same line.
+			line = line[len(match[0]):]
+		}
+		tables := metrics.OpenFlowTables
+		if len(tables) == 0 || tables[len(tables)-1].Bridge != bridge {
+			continue
+		}
+		table := &tables[len(tables)-1]
+		for _, match := range valueRegexp.FindAllStringSubmatch(line, -1) {
+			v, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				metrics.parseFailure(dumpTablesCommand, match[1])
+				continue
+			}
+			table.Values[match[1]] = v
+		}
+	}
+}
+
+func parseOpenFlowPorts(metrics *OvsMetric, bridge string, dumpPorts string) {
+	// e.g. "  port  dpdk0: rx pkts=100, bytes=10000, drop=0, errs=0, ...", with
+	// the port name quoted if it needs to be.
+	portRegexp := regexp.MustCompile(`^[ \t]*port\s+"?([^":]+?)"?:\s*rx (.*)$`)
+	txRegexp := regexp.MustCompile(`^[ \t]*tx (.*)$`)
+	// "?" for counters the port doesn't support
+	valueRegexp := regexp.MustCompile(`(\w+)=(\d+|\?)`)
+
+	for _, line := range strings.Split(dumpPorts, "\n") {
+		direction, counters := "", ""
+		if match := portRegexp.FindStringSubmatch(line); match != nil {
+			metrics.OpenFlowPorts = append(metrics.OpenFlowPorts, OpenFlowPortMetric{Bridge: bridge, Port: strings.TrimSpace(match[1]), Values: make(map[string]float64)})
+			direction, counters = "rx", match[2]
+		} else if match := txRegexp.FindStringSubmatch(line); match != nil {
+			direction, counters = "tx", match[1]
+		} else {
+			continue
+		}
+		ports := metrics.OpenFlowPorts
+		if len(ports) == 0 || ports[len(ports)-1].Bridge != bridge {
+			continue
+		}
+		port := &ports[len(ports)-1]
+		for _, match := range valueRegexp.FindAllStringSubmatch(counters, -1) {
+			if match[2] == "?" {
+				continue
+			}
+			source := direction + " " + match[1]
+			v, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				metrics.parseFailure(dumpPortsCommand, source)
+				continue
+			}
+			port.Values[source] = v
+		}
+	}
+}
+
+func parseMeters(metrics *OvsMetric, bridge string, meterStats string) {
+	// e.g. "meter:1 flow_count:1 packet_in_count:100 byte_in_count:6000 duration:50.123s bands:"
+	meterRegexp := regexp.MustCompile(`^meter:(\S+) (.*)$`)
+	// e.g. "0: packet_count:20 byte_count:1200"
+	bandRegexp := regexp.MustCompile(`^[ \t]*(\d+): (.*)$`)
+	valueRegexp := regexp.MustCompile(`(\w+_count):(\d+)`)
+
+	for _, line := range strings.Split(meterStats, "\n") {
+		var values map[string]float64
+		var counters string
+		if match := meterRegexp.FindStringSubmatch(line); match != nil {
+			meter := MeterMetric{Bridge: bridge, MeterID: match[1], Values: make(map[string]float64)}
+			metrics.Meters = append(metrics.Meters, meter)
+			values, counters = meter.Values, match[2]
+		} else if match := bandRegexp.FindStringSubmatch(line); match != nil {
+			meters := metrics.Meters
+			if len(meters) == 0 || meters[len(meters)-1].Bridge != bridge {
+				continue
+			}
+			meter := &meters[len(meters)-1]
+			meter.Bands = append(meter.Bands, MeterBandMetric{Band: match[1], Values: make(map[string]float64)})
+			values, counters = meter.Bands[len(meter.Bands)-1].Values, match[2]
+		} else {
+			continue
+		}
+		for _, match := range valueRegexp.FindAllStringSubmatch(counters, -1) {
+			v, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				metrics.parseFailure(meterStatsCommand, match[1])
+				continue
+			}
+			values[match[1]] = v
+		}
+	}
+}
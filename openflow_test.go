@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseOpenFlowTables(t *testing.T) {
+	var ovsMetric OvsMetric
+	parseOpenFlowTables(&ovsMetric, "br0", `OFPST_TABLE reply (OF1.3) (xid=0x2):
+  table 0:
+    active=3, lookup=100, matched=90
+
+  table 1 ("acl"):
+    active=0, lookup=10, matched=0
+
+  tables 2...3: ditto
+`)
+
+	expected := OvsMetric{
+		OpenFlowTables: []OpenFlowTableMetric{
+			{Bridge: "br0", Table: "0", Values: map[string]float64{"active": 3, "lookup": 100, "matched": 90}},
+			{Bridge: "br0", Table: "1", Values: map[string]float64{"active": 0, "lookup": 10, "matched": 0}},
+			{Bridge: "br0", Table: "2", Values: map[string]float64{"active": 0, "lookup": 10, "matched": 0}},
+			{Bridge: "br0", Table: "3", Values: map[string]float64{"active": 0, "lookup": 10, "matched": 0}},
+		},
+	}
+	diff := cmp.Diff(ovsMetric, expected)
+	if diff != "" {
+		t.Errorf("Structs are different:\n%s", diff)
+	}
+}
+
+func Test_parseOpenFlowPorts(t *testing.T) {
+	var ovsMetric OvsMetric
+	parseOpenFlowPorts(&ovsMetric, "br0", `OFPST_PORT reply (OF1.3) (xid=0x2): 2 ports
+  port LOCAL: rx pkts=0, bytes=0, drop=0, errs=0, frame=0, over=0, crc=0
+           tx pkts=0, bytes=0, drop=5, errs=0, coll=0
+           duration=1234.567s
+  port  dpdk0: rx pkts=100, bytes=10000, drop=?, errs=1, frame=?, over=?, crc=?
+           tx pkts=50, bytes=5000, drop=2, errs=0, coll=?
+           duration=1234.567s
+`)
+
+	expected := OvsMetric{
+		OpenFlowPorts: []OpenFlowPortMetric{
+			{
+				Bridge: "br0",
+				Port:   "LOCAL",
+				Values: map[string]float64{
+					"rx pkts": 0, "rx bytes": 0, "rx drop": 0, "rx errs": 0, "rx frame": 0, "rx over": 0, "rx crc": 0,
+					"tx pkts": 0, "tx bytes": 0, "tx drop": 5, "tx errs": 0, "tx coll": 0,
+				},
+			},
+			{
+				Bridge: "br0",
+				Port:   "dpdk0",
+				Values: map[string]float64{
+					"rx pkts": 100, "rx bytes": 10000, "rx errs": 1,
+					"tx pkts": 50, "tx bytes": 5000, "tx drop": 2, "tx errs": 0,
+				},
+			},
+		},
+	}
+	diff := cmp.Diff(ovsMetric, expected)
+	if diff != "" {
+		t.Errorf("Structs are different:\n%s", diff)
+	}
+}
+
+func Test_parseMeters(t *testing.T) {
+	var ovsMetric OvsMetric
+	parseMeters(&ovsMetric, "br0", `OFPST_METER reply (OF1.3) (xid=0x2):
+meter:1 flow_count:2 packet_in_count:100 byte_in_count:6000 duration:50.123s bands:
+0: packet_count:20 byte_count:1200
+meter:2 flow_count:0 packet_in_count:0 byte_in_count:0 duration:10.000s bands:
+0: packet_count:0 byte_count:0
+1: packet_count:0 byte_count:0
+`)
+
+	expected := OvsMetric{
+		Meters: []MeterMetric{
+			{
+				Bridge:  "br0",
+				MeterID: "1",
+				Values:  map[string]float64{"flow_count": 2, "packet_in_count": 100, "byte_in_count": 6000},
+				Bands: []MeterBandMetric{
+					{Band: "0", Values: map[string]float64{"packet_count": 20, "byte_count": 1200}},
+				},
+			},
+			{
+				Bridge:  "br0",
+				MeterID: "2",
+				Values:  map[string]float64{"flow_count": 0, "packet_in_count": 0, "byte_in_count": 0},
+				Bands: []MeterBandMetric{
+					{Band: "0", Values: map[string]float64{"packet_count": 0, "byte_count": 0}},
+					{Band: "1", Values: map[string]float64{"packet_count": 0, "byte_count": 0}},
+				},
+			},
+		},
+	}
+	diff := cmp.Diff(ovsMetric, expected)
+	if diff != "" {
+		t.Errorf("Structs are different:\n%s", diff)
+	}
+}
+
+// meterlessOfctl fails meter-stats like ovs-ofctl does on a bridge that
+// doesn't allow OpenFlow 1.3.
+type meterlessOfctl struct {
+	fakeAppctl
+}
+
+func (ctl meterlessOfctl) run(ctx context.Context, command string, args ...string) (string, error) {
+	if command == meterStatsCommand {
+		return "ovs-ofctl: switch does not support any of the usable flow formats (OXM-OpenFlow13,OXM-OpenFlow14,OXM-OpenFlow15)\n", errors.New("exit status 1")
+	}
+	return ctl.fakeAppctl.run(ctx, command, args...)
+}
+
+func Test_getOvsMetricOpenFlow(t *testing.T) {
+	ctl := fakeAppctl{
+		"dpif/show": `netdev@ovs-netdev: hit:0 missed:0
+  br0:
+    br0 65534/1: (tap)
+`,
+	}
+	ofctl := fakeAppctl{
+		"dump-tables br0": "OFPST_TABLE reply (OF1.3) (xid=0x2):\n  table 0:\n    active=1, lookup=2, matched=2\n",
+		"dump-ports br0":  "OFPST_PORT reply (OF1.3) (xid=0x2): 1 ports\n  port LOCAL: rx pkts=1, bytes=2, drop=0, errs=0\n",
+	}
+	ovsMetric := getOvsMetric(context.Background(), ctl, meterlessOfctl{ofctl}, nil)
+
+	if len(ovsMetric.OpenFlowTables) != 1 || len(ovsMetric.OpenFlowPorts) != 1 {
+		t.Errorf("Expected a table and a port, got %v and %v", ovsMetric.OpenFlowTables, ovsMetric.OpenFlowPorts)
+	}
+	for _, command := range ovsMetric.Commands {
+		if command.Command == meterStatsCommand {
+			t.Errorf("Expected meter-stats to be skipped, got %v", command)
+		}
+	}
+	samples := ovsMetric.samples(metricSpec{command: dumpPortsCommand, source: "rx bytes"}, sampleOptions{})
+	expected := []Sample{{Value: 2, Labels: []string{"br0", "LOCAL"}}}
+	diff := cmp.Diff(samples, expected)
+	if diff != "" {
+		t.Errorf("Samples are different:\n%s", diff)
+	}
+}
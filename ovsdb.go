@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ovsdb selects rows of a table of the Open_vSwitch database. The query is
+// abandoned when ctx is done.
+type ovsdb interface {
+	selectRows(ctx context.Context, table string, columns ...string) ([]ovsdbRow, error)
+}
+
+// ovsdbRow maps column names to their values in the OVSDB JSON encoding of
+// RFC 7047.
+type ovsdbRow map[string]json.RawMessage
+
+// ovsdbClient queries ovsdb-server over its Unix socket using the JSON-RPC
+// protocol of RFC 7047.
+type ovsdbClient struct {
+	// socket is the ovsdb-server socket, usually
+	// /var/run/openvswitch/db.sock.
+	socket string
+}
+
+type ovsdbRequest struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     interface{}   `json:"id"`
+}
+
+// ovsdbMessage is either a response to a request or a request from the
+// server, like echo.
+type ovsdbMessage struct {
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+	Result json.RawMessage  `json:"result"`
+	Error  json.RawMessage  `json:"error"`
+	ID     *json.RawMessage `json:"id"`
+}
+
+// ovsdbOperationResult is the result of a transact operation.
+type ovsdbOperationResult struct {
+	Rows    []ovsdbRow `json:"rows"`
+	Error   string     `json:"error"`
+	Details string     `json:"details"`
+}
+
+func (client ovsdbClient) selectRows(ctx context.Context, table string, columns ...string) ([]ovsdbRow, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", client.socket)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Unblock reads and writes when ctx is done.
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	encoder := json.NewEncoder(conn)
+	request := ovsdbRequest{
+		Method: "transact",
+		Params: []interface{}{"Open_vSwitch", map[string]interface{}{
+			"op":      "select",
+			"table":   table,
+			"where":   []interface{}{},
+			"columns": columns,
+		}},
+		ID: 1,
+	}
+	if err := encoder.Encode(request); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var message ovsdbMessage
+		if err := decoder.Decode(&message); err != nil {
+			return nil, err
+		}
+		if message.Method == "echo" {
+			echo := map[string]interface{}{"result": message.Params, "error": nil, "id": message.ID}
+			if err := encoder.Encode(echo); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if message.ID == nil || string(*message.ID) != "1" {
+			continue
+		}
+
+		if len(message.Error) > 0 && string(message.Error) != "null" {
+			return nil, fmt.Errorf("ovsdb transact: %s", message.Error)
+		}
+		var results []ovsdbOperationResult
+		if err := json.Unmarshal(message.Result, &results); err != nil {
+			return nil, fmt.Errorf("ovsdb transact: %w", err)
+		}
+		if len(results) == 0 {
+			return nil, errors.New("ovsdb transact: no operation result")
+		}
+		if results[0].Error != "" {
+			return nil, fmt.Errorf("ovsdb select %s: %s: %s", table, results[0].Error, results[0].Details)
+		}
+		return results[0].Rows, nil
+	}
+}
+
+// timeoutOvsdb bounds each query by timeout and counts the queries that
+// didn't complete in time, like timeoutAppctl.
+type timeoutOvsdb struct {
+	ovsdb    ovsdb
+	timeout  time.Duration
+	timeouts *prometheus.CounterVec
+}
+
+func (db timeoutOvsdb) selectRows(ctx context.Context, table string, columns ...string) ([]ovsdbRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := db.ovsdb.selectRows(ctx, table, columns...)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		db.timeouts.WithLabelValues(ovsdbCommand(table)).Inc()
+		return rows, fmt.Errorf("%s timed out: %w", ovsdbCommand(table), err)
+	}
+	return rows, err
+}
+
+// ovsdbCommand names the select of table in scrape health metrics.
+func ovsdbCommand(table string) string {
+	return "ovsdb/" + table
+}
+
+// ovsdbAtoms decodes the atoms of a set column, a single atom being its own
+// set and a missing column an empty one.
+func ovsdbAtoms(value json.RawMessage) ([]json.RawMessage, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	var set []json.RawMessage
+	if json.Unmarshal(value, &set) != nil || len(set) != 2 {
+		return []json.RawMessage{value}, nil
+	}
+	var kind string
+	if err := json.Unmarshal(set[0], &kind); err != nil || kind != "set" {
+		return []json.RawMessage{value}, nil
+	}
+	var atoms []json.RawMessage
+	if err := json.Unmarshal(set[1], &atoms); err != nil {
+		return nil, err
+	}
+	return atoms, nil
+}
+
+// ovsdbMap decodes a map column into its key and value pairs.
+func ovsdbMap(value json.RawMessage) ([][2]json.RawMessage, error) {
+	var kind string
+	var pairs [][2]json.RawMessage
+	if err := json.Unmarshal(value, &[]interface{}{&kind, &pairs}); err != nil {
+		return nil, err
+	}
+	if kind != "map" {
+		return nil, fmt.Errorf("not a map: %s", value)
+	}
+	return pairs, nil
+}
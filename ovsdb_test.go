@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeOvsdbServer serves RFC 7047 select operations on a Unix socket,
+// answering with the JSON rows of tables or an error for unknown ones. It
+// sends an echo request before each response, as ovsdb-server does to probe
+// idle clients.
+func fakeOvsdbServer(t *testing.T, tables map[string]string) string {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "db.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				decoder := json.NewDecoder(conn)
+				encoder := json.NewEncoder(conn)
+
+				var request struct {
+					Method string            `json:"method"`
+					Params []json.RawMessage `json:"params"`
+					ID     json.RawMessage   `json:"id"`
+				}
+				if err := decoder.Decode(&request); err != nil || request.Method != "transact" || len(request.Params) != 2 {
+					return
+				}
+				var operation struct {
+					Op    string `json:"op"`
+					Table string `json:"table"`
+				}
+				if err := json.Unmarshal(request.Params[1], &operation); err != nil {
+					return
+				}
+
+				encoder.Encode(map[string]interface{}{"method": "echo", "params": []string{}, "id": "echo"})
+				var echo ovsdbMessage
+				if err := decoder.Decode(&echo); err != nil || echo.ID == nil || string(*echo.ID) != `"echo"` {
+					return
+				}
+
+				result := `[{"error":"unknown table","details":"No table named ` + operation.Table + `."}]`
+				if rows, ok := tables[operation.Table]; ok {
+					result = `[{"rows":` + rows + `}]`
+				}
+				encoder.Encode(map[string]interface{}{"id": request.ID, "result": json.RawMessage(result), "error": nil})
+			}()
+		}
+	}()
+
+	return socket
+}
+
+func Test_ovsdbClient(t *testing.T) {
+	socket := fakeOvsdbServer(t, map[string]string{
+		"Interface": `[{"name":"dpdk0","type":"dpdk","mtu":1500}]`,
+	})
+
+	tests := []struct {
+		name   string
+		client ovsdbClient
+		table  string
+		rows   []ovsdbRow
+		err    string
+	}{
+		{
+			name:   "rows",
+			client: ovsdbClient{socket: socket},
+			table:  "Interface",
+			rows:   []ovsdbRow{{"name": json.RawMessage(`"dpdk0"`), "type": json.RawMessage(`"dpdk"`), "mtu": json.RawMessage(`1500`)}},
+		},
+		{
+			name:   "operation error",
+			client: ovsdbClient{socket: socket},
+			table:  "Bond",
+			err:    "ovsdb select Bond: unknown table: No table named Bond.",
+		},
+		{
+			name:   "no socket",
+			client: ovsdbClient{socket: filepath.Join(t.TempDir(), "db.sock")},
+			table:  "Interface",
+			err:    "connect: no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := tt.client.selectRows(context.Background(), tt.table, "name", "type", "mtu")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diff := cmp.Diff(rows, tt.rows)
+			if diff != "" {
+				t.Errorf("Rows are different:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ovsdbClientTimeout(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "db.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := (ovsdbClient{socket: socket}).selectRows(ctx, "Interface"); err == nil {
+		t.Fatal("Expected an error from a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Query took %s to be abandoned", elapsed)
+	}
+}
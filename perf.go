@@ -0,0 +1,154 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PMDPerfMetric holds the performance metrics of a PMD thread from
+// pmd-perf-show.
+type PMDPerfMetric struct {
+	NumaID string
+	CoreID string
+	// Values are keyed by the text before the colon of each overall stats
+	// line, without the leading "- ". The upcall cost is keyed as
+	// "Upcalls us/upcall".
+	Values map[string]float64
+	// Histograms are keyed by their column name, e.g. "pkts/batch".
+	Histograms map[string]PMDPerfHistogram
+}
+
+// PMDPerfHistogram is a histogram printed by pmd-perf-show with extended
+// metrics enabled. Buckets are cumulative counts by upper bound. The sum is
+// estimated from the average printed below the histograms.
+type PMDPerfHistogram struct {
+	Buckets map[float64]uint64
+	Count   uint64
+	Sum     float64
+}
+
+// pmdPerfSamples returns the values of a pmd-perf-show registry metric.
+func (metrics *OvsMetric) pmdPerfSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, pmd := range metrics.PMDPerfs {
+		labels := []string{pmd.NumaID, pmd.CoreID}
+		if spec.histogram {
+			if histogram, ok := pmd.Histograms[spec.source]; ok {
+				samples = append(samples, Sample{Value: histogram.Sum, Count: histogram.Count, Buckets: histogram.Buckets, Labels: labels})
+			}
+		} else if v, ok := pmd.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: labels})
+		}
+	}
+	return samples
+}
+
+func parsePMDPerf(metrics *OvsMetric, pmdPerf string) {
+	metrics.PMDPerfs = nil
+	for _, section := range splitPMDSections(pmdPerf) {
+		if section.thread != "pmd" {
+			continue
+		}
+		pmd := PMDPerfMetric{NumaID: section.numaID, CoreID: section.coreID}
+
+		overall, histograms, _ := strings.Cut(section.body, "Histograms")
+		parsePMDPerfOverall(metrics, &pmd, overall)
+		parsePMDPerfHistograms(metrics, &pmd, histograms)
+
+		metrics.PMDPerfs = append(metrics.PMDPerfs, pmd)
+	}
+}
+
+func parsePMDPerfOverall(metrics *OvsMetric, pmd *PMDPerfMetric, overall string) {
+	// e.g. "  - Used TSC cycles: 259913405484  ( 99.9 % of total cycles)"
+	lineRegexp := regexp.MustCompile(`(?m)^[ \t]*(?:- )?([A-Za-z][\w ()/]*?):\s*(\d+(?:\.\d+)?)`)
+	upcallRegexp := regexp.MustCompile(`(?m)^[ \t]*- Upcalls:.*?(\d+(?:\.\d+)?) us/upcall`)
+
+	pmd.Values = make(map[string]float64)
+	for _, match := range lineRegexp.FindAllStringSubmatch(overall, -1) {
+		v, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			metrics.parseFailure(pmdPerfCommand, match[1])
+			continue
+		}
+		pmd.Values[match[1]] = v
+	}
+	if match := upcallRegexp.FindStringSubmatch(overall); match != nil {
+		v, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			metrics.parseFailure(pmdPerfCommand, "Upcalls us/upcall")
+		} else {
+			pmd.Values["Upcalls us/upcall"] = v
+		}
+	}
+}
+
+// parsePMDPerfHistograms parses the histograms table: a header of column
+// names, a row of upper bound and count pairs per bucket ending with the ">"
+// overflow bucket, then optionally the averages of each column.
+func parsePMDPerfHistograms(metrics *OvsMetric, pmd *PMDPerfMetric, histograms string) {
+	columnRegexp := regexp.MustCompile(`\S+(?: \S+)*`)
+
+	lines := strings.Split(histograms, "\n")
+	var columns []string
+	var rows [][]string
+	var averages []string
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0 || strings.HasPrefix(fields[0], "---"):
+		case columns == nil && !isNumber(fields[0]):
+			columns = columnRegexp.FindAllString(strings.TrimSpace(line), -1)
+		case rows == nil || rows[len(rows)-1][0] != ">":
+			rows = append(rows, fields)
+		case isNumber(fields[0]):
+			averages = fields
+		}
+	}
+	if columns == nil {
+		return
+	}
+
+	pmd.Histograms = make(map[string]PMDPerfHistogram)
+	for i, column := range columns {
+		histogram := PMDPerfHistogram{Buckets: make(map[float64]uint64)}
+		valid := true
+		for _, row := range rows {
+			if len(row) < 2*len(columns) {
+				valid = false
+				break
+			}
+			count, err := strconv.ParseUint(row[2*i+1], 10, 64)
+			if err != nil {
+				valid = false
+				break
+			}
+			histogram.Count += count
+			if row[2*i] == ">" {
+				continue
+			}
+			bound, err := strconv.ParseFloat(row[2*i], 64)
+			if err != nil {
+				valid = false
+				break
+			}
+			histogram.Buckets[bound] = histogram.Count
+		}
+		if !valid {
+			metrics.parseFailure(pmdPerfCommand, column)
+			continue
+		}
+		if i < len(averages) {
+			if average, err := strconv.ParseFloat(averages[i], 64); err == nil {
+				histogram.Sum = average * float64(histogram.Count)
+			}
+		}
+		pmd.Histograms[column] = histogram
+	}
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parsePMDPerf(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `Time: 13:05:13.034
+Measurement duration: 104.025 s
+
+pmd thread numa_id 0 core_id 1:
+
+  Iterations:             49999452  (2.08 us/it)
+  - Used TSC cycles:  259913405484  ( 99.9 % of total cycles)
+  - idle iterations:      49999337  ( 99.0 % of used cycles)
+  - busy iterations:           115  (  1.0 % of used cycles)
+  Rx packets:                  115  (0 Kpps, 20947 cycles/pkt)
+  Datapath passes:             115  (1.00 passes/pkt)
+  - EMC hits:                  100  ( 87.0 %)
+  - Megaflow hits:              10  (  8.7 %, 1.00 subtbl lookups/hit)
+  - Upcalls:                     5  (  4.3 %, 140.5 us/upcall)
+  - Lost upcalls:                0  (  0.0 %)
+  Tx packets:                  115  (0 Kpps)
+  Tx batches:                  115  (1.00 pkts/batch)
+
+  Histograms
+   cycles/it              packets/it             cycles/pkt             pkts/batch             max vhost qlen         upcalls/it             cycles/upcall
+   500       49999337     0         49999337     1000      0            1         115          0         49999452     0         49999447     1000      0
+   1000      0            1         115          10000     100          2         0            1         0            1         5            100000    3
+   >         115          >         0            >         15           >         0            >         0            >         0            >         2
+  ----------------------------------------------------------------------------------------------------------------------------------------------------------
+   cycles/it              packets/it             cycles/pkt             pkts/batch             vhost qlen             upcalls/it             cycles/upcall
+   5198                   0.00000                20947                  1.00000                0.00000                0.00000                281000
+`,
+			metric: OvsMetric{
+				PMDPerfs: []PMDPerfMetric{
+					{
+						NumaID: "0",
+						CoreID: "1",
+						Values: map[string]float64{
+							"Iterations":        49999452,
+							"Used TSC cycles":   259913405484,
+							"idle iterations":   49999337,
+							"busy iterations":   115,
+							"Rx packets":        115,
+							"Datapath passes":   115,
+							"EMC hits":          100,
+							"Megaflow hits":     10,
+							"Upcalls":           5,
+							"Upcalls us/upcall": 140.5,
+							"Lost upcalls":      0,
+							"Tx packets":        115,
+							"Tx batches":        115,
+						},
+						Histograms: map[string]PMDPerfHistogram{
+							"cycles/it":      {Buckets: map[float64]uint64{500: 49999337, 1000: 49999337}, Count: 49999452, Sum: 5198 * 49999452},
+							"packets/it":     {Buckets: map[float64]uint64{0: 49999337, 1: 49999452}, Count: 49999452, Sum: 0},
+							"cycles/pkt":     {Buckets: map[float64]uint64{1000: 0, 10000: 100}, Count: 115, Sum: 20947 * 115},
+							"pkts/batch":     {Buckets: map[float64]uint64{1: 115, 2: 115}, Count: 115, Sum: 115},
+							"max vhost qlen": {Buckets: map[float64]uint64{0: 49999452, 1: 49999452}, Count: 49999452, Sum: 0},
+							"upcalls/it":     {Buckets: map[float64]uint64{0: 49999447, 1: 49999452}, Count: 49999452, Sum: 0},
+							"cycles/upcall":  {Buckets: map[float64]uint64{1000: 0, 100000: 3}, Count: 5, Sum: 281000 * 5},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "without histograms",
+			output: `pmd thread numa_id 1 core_id 34:
+
+  Iterations:                 1000  (0.39 us/it)
+  - Used TSC cycles:       1000000  (100.0 % of total cycles)
+  Rx packets:                    0`,
+			metric: OvsMetric{
+				PMDPerfs: []PMDPerfMetric{
+					{
+						NumaID: "1",
+						CoreID: "34",
+						Values: map[string]float64{
+							"Iterations":      1000,
+							"Used TSC cycles": 1000000,
+							"Rx packets":      0,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "truncated histograms",
+			output: `pmd thread numa_id 0 core_id 1:
+
+  Iterations:                 1000  (0.39 us/it)
+
+  Histograms
+   cycles/it              pkts/batch
+   500       1000         1         0
+   >         0`,
+			metric: OvsMetric{
+				PMDPerfs: []PMDPerfMetric{
+					{
+						NumaID:     "0",
+						CoreID:     "1",
+						Values:     map[string]float64{"Iterations": 1000},
+						Histograms: map[string]PMDPerfHistogram{},
+					},
+				},
+				ParseFailures: []string{"ovsdp_pmd_perf_cycles_per_iteration", "ovsdp_pmd_perf_packets_per_batch"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parsePMDPerf(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,1672 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	pmdStatsCommand        = "dpif-netdev/pmd-stats-show"
+	coverageCommand        = "coverage/show"
+	pmdRxqCommand          = "dpif-netdev/pmd-rxq-show"
+	pmdPerfCommand         = "dpif-netdev/pmd-perf-show"
+	ctStatsCommand         = "dpctl/ct-stats-show"
+	ctNConnsCommand        = "dpctl/ct-get-nconns"
+	ctMaxConnsCommand      = "dpctl/ct-get-maxconns"
+	ctLimitsCommand        = "dpctl/ct-get-limits"
+	upcallCommand          = "upcall/show"
+	dpctlShowCommand       = "dpctl/show"
+	dpifShowCommand        = "dpif/show"
+	offloadStatsCommand    = "dpctl/offload-stats-show"
+	dpdkMempoolCommand     = "netdev-dpdk/get-mempool-info"
+	dpdkMallocCommand      = "dpdk/get-malloc-stats"
+	bondCommand            = "bond/show"
+	lacpCommand            = "lacp/show"
+	bfdCommand             = "bfd/show"
+	cfmCommand             = "cfm/show"
+	tunnelPortsCommand     = "tnl/ports/show"
+	tunnelNeighborsCommand = "tnl/neigh/show"
+	// fdbStatsCommand is run per bridge.
+	fdbStatsCommand = "fdb/stats-show"
+	// The ovs-ofctl commands are run per bridge.
+	dumpTablesCommand = "dump-tables"
+	dumpPortsCommand  = "dump-ports"
+	meterStatsCommand = "meter-stats"
+	// interfaceCommand is the select of the OVSDB Interface table.
+	interfaceCommand = "ovsdb/Interface"
+)
+
+// metricSpec declares a metric exported from a value printed by an OVS
+// command.
+type metricSpec struct {
+	// command is the ovs-appctl or ovs-ofctl command printing the value.
+	command string
+	// source names the value in the command output: the text before the
+	// colon for pmd-stats-show, with a " %" suffix for the percentage in
+	// parentheses, the counter name for coverage/show, or total or rate for
+	// every counter, or the key of the parsed values for other commands.
+	source    string
+	name      string
+	help      string
+	valueType prometheus.ValueType
+	labels    []string
+	// histogram exports the source as a histogram instead of valueType.
+	histogram bool
+}
+
+// pmdThreadLabels are the labels of metrics reported per pmd-stats-show
+// thread section. numa_id and core_id are empty for the main thread.
+var pmdThreadLabels = []string{"numa_id", "core_id", "thread"}
+
+// coverageLabels and coverageRateLabels are the labels of metrics reported
+// for every coverage/show counter.
+var (
+	coverageLabels     = []string{"counter"}
+	coverageRateLabels = []string{"counter", "window"}
+)
+
+// pmdLabels and rxqLabels are the labels of metrics reported per PMD thread
+// by pmd-rxq-show and pmd-perf-show, and per rx queue by pmd-rxq-show.
+var (
+	pmdLabels = []string{"numa_id", "core_id"}
+	rxqLabels = []string{"numa_id", "core_id", "port", "queue_id"}
+)
+
+// datapathLabels and revalidatorLabels are the labels of metrics reported
+// per datapath by upcall/show and dpctl/show, and per revalidator thread by
+// upcall/show.
+var (
+	datapathLabels    = []string{"datapath"}
+	revalidatorLabels = []string{"datapath", "revalidator"}
+)
+
+// bridgeLabels are the labels of metrics reported by the commands run per
+// bridge, and bridgePortLabels of metrics reported per bridge port by
+// dpif/show.
+var (
+	bridgeLabels     = []string{"bridge"}
+	bridgePortLabels = []string{"datapath", "bridge", "port"}
+)
+
+// openFlowTableLabels, openFlowPortLabels, meterLabels and meterBandLabels
+// are the labels of metrics reported per OpenFlow table by dump-tables, per
+// OpenFlow port by dump-ports, and per meter and meter band by meter-stats.
+var (
+	openFlowTableLabels = []string{"bridge", "table_id"}
+	openFlowPortLabels  = []string{"bridge", "port"}
+	meterLabels         = []string{"bridge", "meter_id"}
+	meterBandLabels     = []string{"bridge", "meter_id", "band"}
+)
+
+// offloadThreadLabels are the labels of metrics reported per offload thread
+// by offload-stats-show.
+var offloadThreadLabels = []string{"thread"}
+
+// mempoolLabels and heapLabels are the labels of metrics reported per DPDK
+// mempool by get-mempool-info and per malloc heap by get-malloc-stats.
+var (
+	mempoolLabels = []string{"mempool", "socket"}
+	heapLabels    = []string{"heap"}
+)
+
+// bondLabels and bondMemberLabels are the labels of metrics reported per bond
+// and per bond member by bond/show and lacp/show.
+var (
+	bondLabels       = []string{"bond"}
+	bondMemberLabels = []string{"bond", "member"}
+)
+
+// sessionLabels are the labels of metrics reported per interface by
+// bfd/show and cfm/show.
+var sessionLabels = []string{"interface"}
+
+// interfaceLabels are the labels of metrics reported per row of the OVSDB
+// Interface table. type is empty for system interfaces.
+var interfaceLabels = []string{"interface", "type"}
+
+// metricRegistry lists the metrics parsed from OVS command output. Adding a
+// metric only takes a new entry here.
+var metricRegistry = []metricSpec{
+	// PMD stats
+	{
+		command:   pmdStatsCommand,
+		source:    "miss with success upcall",
+		name:      "ovsdp_miss_with_success_upcall",
+		help:      "Cache miss with successuful upcall",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "miss with failed upcall",
+		name:      "ovsdp_miss_with_failed_upcall",
+		help:      "Cache miss with failed upcall",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "processing cycles %",
+		name:      "ovsdp_processing_cycles",
+		help:      "Percentage of the CPU cycles of the thread spent processing packets, see ovsdp_pmd_processing_cycles_total for the raw count",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "idle cycles %",
+		name:      "ovsdp_idle_cycles",
+		help:      "Percentage of the CPU cycles of the thread spent idle polling for packets, see ovsdp_pmd_idle_cycles_total for the raw count",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "avg. subtable lookups per megaflow hit",
+		name:      "ovsdp_avg_subtable_lookups_megaflow",
+		help:      "Average of subtable lookups per megaflow hit",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "packets received",
+		name:      "ovsdp_packets_received",
+		help:      "Packets received by the thread",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "packet recirculations",
+		name:      "ovsdp_packet_recirculations",
+		help:      "Packets recirculated by the thread",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "avg. datapath passes per packet",
+		name:      "ovsdp_avg_datapath_passes_per_packet",
+		help:      "Average number of datapath passes per packet, including recirculations",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "phwol hits",
+		name:      "ovsdp_phwol_hits",
+		help:      "Packets matched by partial hardware offload",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "mfex opt hits",
+		name:      "ovsdp_mfex_opt_hits",
+		help:      "Packets handled by the optimized miniflow extract",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "simple match hits",
+		name:      "ovsdp_simple_match_hits",
+		help:      "Packets matched in the simple match cache",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "emc hits",
+		name:      "ovsdp_emc_hits",
+		help:      "Packets matched in the exact match cache",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "smc hits",
+		name:      "ovsdp_smc_hits",
+		help:      "Packets matched in the signature match cache",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "megaflow hits",
+		name:      "ovsdp_megaflow_hits",
+		help:      "Packets matched in the megaflow cache",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "avg. packets per output batch",
+		name:      "ovsdp_avg_packets_per_output_batch",
+		help:      "Average number of packets per output batch",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "idle cycles",
+		name:      "ovsdp_pmd_idle_cycles_total",
+		help:      "Number of CPU cycles the thread spent idle polling for packets, see ovsdp_idle_cycles for the percentage",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "processing cycles",
+		name:      "ovsdp_pmd_processing_cycles_total",
+		help:      "Number of CPU cycles the thread spent processing packets, see ovsdp_processing_cycles for the percentage",
+		valueType: prometheus.CounterValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "avg cycles per packet",
+		name:      "ovsdp_avg_cycles_per_packet",
+		help:      "Average CPU cycles, idle and processing, per packet received",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdThreadLabels,
+	},
+	{
+		command:   pmdStatsCommand,
+		source:    "avg processing cycles per packet",
+		name:      "ovsdp_avg_processing_cycles_per_packet",
+		help:      "Average processing CPU cycles per packet received",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdThreadLabels,
+	},
+	// Drop reasons
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_upcall_error",
+		name:      "ovsdp_datapath_drop_upcall_error",
+		help:      "Drop packet due to error in the Upcall process",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_lock_error",
+		name:      "ovsdp_datapath_drop_lock_error",
+		help:      "Drop packet due to Upcall lock contention",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_rx_invalid_packet",
+		name:      "ovsdp_datapath_drop_rx_invalid_packet",
+		help:      "Drop invalid packet having size lower than what wrote in the Ethernet header",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_meter",
+		name:      "ovsdp_datapath_drop_meter",
+		help:      "Drop packet in the OpenFlow (1.3+) Meter Table",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_userspace_action_error",
+		name:      "ovsdp_datapath_drop_userspace_action_error",
+		help:      "Drop packet due to generic error executing the action",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_tunnel_push_error",
+		name:      "ovsdp_datapath_drop_tunnel_push_error",
+		help:      "Drop packet due to error executing the tunnel push (aka encapsulation) action",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_tunnel_pop_error",
+		name:      "ovsdp_datapath_drop_tunnel_pop_error",
+		help:      "Drop packet due to error executing the tunnel pop (aka decapsulation) action",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_recirc_error",
+		name:      "ovsdp_datapath_drop_recirc_error",
+		help:      "Drop packet due to error in the recirculation (this can also happen in the tunnel pop action)",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_invalid_port",
+		name:      "ovsdp_datapath_drop_invalid_port",
+		help:      "Drop packet due to invalid port",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_invalid_tnl_port",
+		name:      "ovsdp_datapath_drop_invalid_tnl_port",
+		help:      "Drop packet due to invalid tunnel port executing the pop action",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_sample_error",
+		name:      "ovsdp_datapath_drop_sample_error",
+		help:      "Drop packet due to sampling error",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_nsh_decap_error",
+		name:      "ovsdp_datapath_drop_nsh_decap_error",
+		help:      "Drop packet due to invalid NSH pop (aka decapsulation)",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_of_pipeline",
+		name:      "ovsdp_drop_action_of_pipeline",
+		help:      "Drop packet due to pipeline errors, e.g., error parsing datapath actions",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_bridge_not_found",
+		name:      "ovsdp_drop_action_bridge_not_found",
+		help:      "Drop packet due to bridge not found but, at time of translation, existing",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_recursion_too_deep",
+		name:      "ovsdp_drop_action_recursion_too_deep",
+		help:      "Drop packet due to too many translations, system limit to protect from excessive time/space usage",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_too_many_resubmit",
+		name:      "ovsdp_drop_action_too_many_resubmit",
+		help:      "Drop packet due to too many resubmitted, system limit to protect from excessive time/space usage",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_stack_too_deep",
+		name:      "ovsdp_drop_action_stack_too_deep",
+		help:      "Drop packet due to the stack consuming more than 64 kB, system limit to protect from excessive time/space usage",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_no_recirculation_context",
+		name:      "ovsdp_drop_action_no_recirculation_context",
+		help:      "Drop packet due to missing recirculation context",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_recirculation_conflict",
+		name:      "ovsdp_drop_action_recirculation_conflict",
+		help:      "Drop packet due to conflict in the recirculation",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_too_many_mpls_labels",
+		name:      "ovsdp_drop_action_too_many_mpls_labels",
+		help:      "Drop packet due to MPLS pop action can't be performed as it has more labels than supported (in OVS 2.13 up to 3)",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_invalid_tunnel_metadata",
+		name:      "ovsdp_drop_action_invalid_tunnel_metadata",
+		help:      "Drop packet due to invalid GENEVE tunnel metadata",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_unsupported_packet_type",
+		name:      "ovsdp_drop_action_unsupported_packet_type",
+		help:      "Drop packet due to unsupported packet type (e.g. Ethernet VLAN encapsulation)",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_congestion",
+		name:      "ovsdp_drop_action_congestion",
+		help:      "Drop packet due to congestion ECN (Explicit Congestion Notification) mismatch",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "drop_action_forwarding_disabled",
+		name:      "ovsdp_drop_action_forwarding_disabled",
+		help:      "Drop packet when forwarding for a port is disabled (e.g. when port is admin down)",
+		valueType: prometheus.CounterValue,
+	},
+	// Drop reasons new
+	{
+		command:   coverageCommand,
+		source:    "netdev_vxlan_tso_drops",
+		name:      "ovsdp_netdev_vxlan_tso_drops",
+		help:      "Drop packet due to VXLAN TSO (TCP Segmentation Offload) issues",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "netdev_geneve_tso_drops",
+		name:      "ovsdp_netdev_geneve_tso_drops",
+		help:      "Drop packet due to Geneve TSO (TCP Segmentation Offload) issues",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "netdev_push_header_drops",
+		name:      "ovsdp_netdev_push_header_drops",
+		help:      "Drop packet due to push header errors",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "netdev_soft_seg_drops",
+		name:      "ovsdp_netdev_soft_seg_drops",
+		help:      "Drop packet due to soft segmentation issues",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_tunnel_tso_recirc",
+		name:      "ovsdp_datapath_drop_tunnel_tso_recirc",
+		help:      "Drop packet due to tunnel TSO recirculation errors",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_invalid_bond",
+		name:      "ovsdp_datapath_drop_invalid_bond",
+		help:      "Drop packet due to invalid bond configuration",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "datapath_drop_hw_miss_recover",
+		name:      "ovsdp_datapath_drop_hw_miss_recover",
+		help:      "Drop packet due to hardware miss recovery failure",
+		valueType: prometheus.CounterValue,
+	},
+	// DOCA
+	{
+		command:   coverageCommand,
+		source:    "ovs_doca_no_mark",
+		name:      "ovsdp_ovs_doca_no_mark",
+		help:      "Number of packets dropped due to missing mark in OVS-DOCA",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "ovs_doca_invalid_classify_port",
+		name:      "ovsdp_ovs_doca_invalid_classify_port",
+		help:      "Number of packets dropped due to invalid classify port in OVS-DOCA",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "doca_queue_empty",
+		name:      "ovsdp_doca_queue_empty",
+		help:      "Number of times an offload queue is found empty during completion operations",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "doca_queue_none_processed",
+		name:      "ovsdp_doca_queue_none_processed",
+		help:      "Number of times no entries were processed from a queue despite pending entries",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "doca_resize_block",
+		name:      "ovsdp_doca_resize_block",
+		help:      "Number of times queue processing is blocked due to pipeline resizing when no entries are processed",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "doca_pipe_resize",
+		name:      "ovsdp_doca_pipe_resize",
+		help:      "Number of times a pipe resize operation begins",
+		valueType: prometheus.CounterValue,
+	},
+	{
+		command:   coverageCommand,
+		source:    "doca_pipe_resize_over_10_ms",
+		name:      "ovsdp_doca_pipe_resize_over_10_ms",
+		help:      "Number of times a pipe resize operation takes longer than 10ms",
+		valueType: prometheus.CounterValue,
+	},
+	// Every coverage/show counter
+	{
+		command:   coverageCommand,
+		source:    "total",
+		name:      "ovsdp_coverage_total",
+		help:      "Total number of events counted by an OVS coverage counter",
+		valueType: prometheus.CounterValue,
+		labels:    coverageLabels,
+	},
+	{
+		command:   coverageCommand,
+		source:    "rate",
+		name:      "ovsdp_coverage_rate",
+		help:      "Average events per second counted by an OVS coverage counter over the window",
+		valueType: prometheus.GaugeValue,
+		labels:    coverageRateLabels,
+	},
+	// PMD rx queues
+	{
+		command:   pmdRxqCommand,
+		source:    "isolated",
+		name:      "ovsdp_pmd_isolated",
+		help:      "Whether the PMD thread is isolated, only polling the rx queues pinned to it",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdRxqCommand,
+		source:    "overhead",
+		name:      "ovsdp_pmd_overhead",
+		help:      "Percentage of PMD thread cycles not attributed to polling its rx queues",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdRxqCommand,
+		source:    "pmd usage",
+		name:      "ovsdp_pmd_rxq_usage",
+		help:      "Percentage of PMD thread cycles spent processing the rx queue",
+		valueType: prometheus.GaugeValue,
+		labels:    rxqLabels,
+	},
+	{
+		command:   pmdRxqCommand,
+		source:    "enabled",
+		name:      "ovsdp_pmd_rxq_enabled",
+		help:      "Whether the rx queue is enabled",
+		valueType: prometheus.GaugeValue,
+		labels:    rxqLabels,
+	},
+	// PMD performance
+	{
+		command:   pmdPerfCommand,
+		source:    "Iterations",
+		name:      "ovsdp_pmd_perf_iterations",
+		help:      "Number of PMD thread loop iterations",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "Used TSC cycles",
+		name:      "ovsdp_pmd_perf_used_tsc_cycles",
+		help:      "Number of TSC cycles used by the PMD thread",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "idle iterations",
+		name:      "ovsdp_pmd_perf_idle_iterations",
+		help:      "Number of PMD thread loop iterations without packets",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "busy iterations",
+		name:      "ovsdp_pmd_perf_busy_iterations",
+		help:      "Number of PMD thread loop iterations processing packets",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "sleep iterations",
+		name:      "ovsdp_pmd_perf_sleep_iterations",
+		help:      "Number of PMD thread loop iterations that slept",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "Rx packets",
+		name:      "ovsdp_pmd_perf_rx_packets",
+		help:      "Number of packets received by the PMD thread",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "Upcalls",
+		name:      "ovsdp_pmd_perf_upcalls",
+		help:      "Number of upcalls of the PMD thread",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "Lost upcalls",
+		name:      "ovsdp_pmd_perf_lost_upcalls",
+		help:      "Number of upcalls of the PMD thread that were lost",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "vhost qfull",
+		name:      "ovsdp_pmd_perf_vhost_qfull",
+		help:      "Number of times the PMD thread found a vhost tx queue full",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "Tx packets",
+		name:      "ovsdp_pmd_perf_tx_packets",
+		help:      "Number of packets sent by the PMD thread",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "Tx batches",
+		name:      "ovsdp_pmd_perf_tx_batches",
+		help:      "Number of packet batches sent by the PMD thread",
+		valueType: prometheus.CounterValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "Upcalls us/upcall",
+		name:      "ovsdp_pmd_perf_avg_upcall_microseconds",
+		help:      "Average time spent handling an upcall of the PMD thread",
+		valueType: prometheus.GaugeValue,
+		labels:    pmdLabels,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "cycles/it",
+		name:      "ovsdp_pmd_perf_cycles_per_iteration",
+		help:      "Histogram of the TSC cycles of PMD thread loop iterations",
+		labels:    pmdLabels,
+		histogram: true,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "packets/it",
+		name:      "ovsdp_pmd_perf_packets_per_iteration",
+		help:      "Histogram of the packets received by PMD thread loop iterations",
+		labels:    pmdLabels,
+		histogram: true,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "cycles/pkt",
+		name:      "ovsdp_pmd_perf_cycles_per_packet",
+		help:      "Histogram of the TSC cycles per packet of busy PMD thread loop iterations",
+		labels:    pmdLabels,
+		histogram: true,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "pkts/batch",
+		name:      "ovsdp_pmd_perf_packets_per_batch",
+		help:      "Histogram of the packets per rx batch of the PMD thread",
+		labels:    pmdLabels,
+		histogram: true,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "max vhost qlen",
+		name:      "ovsdp_pmd_perf_max_vhost_queue_length",
+		help:      "Histogram of the maximum vhost rx queue length seen by PMD thread loop iterations",
+		labels:    pmdLabels,
+		histogram: true,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "upcalls/it",
+		name:      "ovsdp_pmd_perf_upcalls_per_iteration",
+		help:      "Histogram of the upcalls of PMD thread loop iterations",
+		labels:    pmdLabels,
+		histogram: true,
+	},
+	{
+		command:   pmdPerfCommand,
+		source:    "cycles/upcall",
+		name:      "ovsdp_pmd_perf_cycles_per_upcall",
+		help:      "Histogram of the TSC cycles spent handling an upcall of the PMD thread",
+		labels:    pmdLabels,
+		histogram: true,
+	},
+	// Conntrack
+	{
+		command:   ctStatsCommand,
+		source:    "protocol",
+		name:      "ovsdp_ct_protocol_connections",
+		help:      "Number of userspace conntrack connections by protocol",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"protocol"},
+	},
+	{
+		command:   ctStatsCommand,
+		source:    "tcp state",
+		name:      "ovsdp_ct_tcp_state_connections",
+		help:      "Number of userspace conntrack TCP connections by state",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"state"},
+	},
+	{
+		command:   ctNConnsCommand,
+		source:    "connections",
+		name:      "ovsdp_ct_connections",
+		help:      "Number of userspace conntrack connections",
+		valueType: prometheus.GaugeValue,
+	},
+	{
+		command:   ctMaxConnsCommand,
+		source:    "max connections",
+		name:      "ovsdp_ct_max_connections",
+		help:      "Maximum number of userspace conntrack connections",
+		valueType: prometheus.GaugeValue,
+	},
+	{
+		command:   ctNConnsCommand,
+		source:    "utilization",
+		name:      "ovsdp_ct_utilization_ratio",
+		help:      "Ratio of the userspace conntrack connections to their maximum",
+		valueType: prometheus.GaugeValue,
+	},
+	{
+		command:   ctLimitsCommand,
+		source:    "limit",
+		name:      "ovsdp_ct_zone_limit",
+		help:      "Maximum number of userspace conntrack connections in the zone, 0 if unlimited",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"zone"},
+	},
+	{
+		command:   ctLimitsCommand,
+		source:    "count",
+		name:      "ovsdp_ct_zone_count",
+		help:      "Number of userspace conntrack connections in the zone",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"zone"},
+	},
+	{
+		command:   ctLimitsCommand,
+		source:    "zones",
+		name:      "ovsdp_ct_zones",
+		help:      "Number of conntrack zones with a limit, including those not exported",
+		valueType: prometheus.GaugeValue,
+	},
+	// Upcalls
+	{
+		command:   upcallCommand,
+		source:    "flows",
+		name:      "ovsdp_upcall_flows",
+		help:      "Number of flows in the datapath",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   upcallCommand,
+		source:    "avg flows",
+		name:      "ovsdp_upcall_avg_flows",
+		help:      "Average number of flows in the datapath over recent dumps",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   upcallCommand,
+		source:    "max flows",
+		name:      "ovsdp_upcall_max_flows",
+		help:      "Maximum number of flows in the datapath over recent dumps",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   upcallCommand,
+		source:    "flow limit",
+		name:      "ovsdp_upcall_flow_limit",
+		help:      "Number of flows above which the datapath stops installing new flows, adjusted to the dump duration",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   upcallCommand,
+		source:    "offloaded flows",
+		name:      "ovsdp_upcall_offloaded_flows",
+		help:      "Number of flows of the datapath offloaded to hardware",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   upcallCommand,
+		source:    "dump duration",
+		name:      "ovsdp_upcall_dump_duration_seconds",
+		help:      "Duration of the last dump of the datapath flows by the revalidators",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   upcallCommand,
+		source:    "ufid enabled",
+		name:      "ovsdp_upcall_ufid_enabled",
+		help:      "Whether the datapath flows are identified by unique flow identifiers",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   upcallCommand,
+		source:    "keys",
+		name:      "ovsdp_upcall_revalidator_keys",
+		help:      "Number of flow keys owned by the revalidator thread",
+		valueType: prometheus.GaugeValue,
+		labels:    revalidatorLabels,
+	},
+	// Datapaths
+	{
+		command:   dpctlShowCommand,
+		source:    "lookups hit",
+		name:      "ovsdp_datapath_lookups_hit",
+		help:      "Number of packets matching an existing datapath flow",
+		valueType: prometheus.CounterValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "lookups missed",
+		name:      "ovsdp_datapath_lookups_missed",
+		help:      "Number of packets matching no datapath flow, sent to userspace",
+		valueType: prometheus.CounterValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "lookups lost",
+		name:      "ovsdp_datapath_lookups_lost",
+		help:      "Number of packets dropped before reaching userspace",
+		valueType: prometheus.CounterValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "flows",
+		name:      "ovsdp_datapath_flows",
+		help:      "Number of flows in the datapath",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "masks hit",
+		name:      "ovsdp_datapath_masks_hit",
+		help:      "Number of masks visited while looking up packets in the datapath",
+		valueType: prometheus.CounterValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "masks total",
+		name:      "ovsdp_datapath_masks",
+		help:      "Number of masks in the datapath",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "masks hit/pkt",
+		name:      "ovsdp_datapath_masks_hit_per_packet",
+		help:      "Average number of masks visited per packet looked up in the datapath",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "cache hit",
+		name:      "ovsdp_datapath_cache_hit",
+		help:      "Number of packets whose mask was found in the datapath mask cache",
+		valueType: prometheus.CounterValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpctlShowCommand,
+		source:    "cache hit-rate",
+		name:      "ovsdp_datapath_cache_hit_rate",
+		help:      "Percentage of packets whose mask was found in the datapath mask cache",
+		valueType: prometheus.GaugeValue,
+		labels:    datapathLabels,
+	},
+	{
+		command:   dpifShowCommand,
+		source:    "datapath port",
+		name:      "ovsdp_datapath_port_number",
+		help:      "Number of the bridge port in its datapath",
+		valueType: prometheus.GaugeValue,
+		labels:    bridgePortLabels,
+	},
+	// Interfaces
+	{
+		command:   interfaceCommand,
+		source:    "rx_packets",
+		name:      "ovsdp_interface_rx_packets",
+		help:      "Number of packets received by the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "tx_packets",
+		name:      "ovsdp_interface_tx_packets",
+		help:      "Number of packets sent by the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "rx_bytes",
+		name:      "ovsdp_interface_rx_bytes",
+		help:      "Number of bytes received by the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "tx_bytes",
+		name:      "ovsdp_interface_tx_bytes",
+		help:      "Number of bytes sent by the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "rx_dropped",
+		name:      "ovsdp_interface_rx_dropped",
+		help:      "Number of packets dropped on receive by the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "tx_dropped",
+		name:      "ovsdp_interface_tx_dropped",
+		help:      "Number of packets dropped on transmit by the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "rx_errors",
+		name:      "ovsdp_interface_rx_errors",
+		help:      "Number of receive errors of the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "tx_errors",
+		name:      "ovsdp_interface_tx_errors",
+		help:      "Number of transmit errors of the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "rx_crc_err",
+		name:      "ovsdp_interface_rx_crc_errors",
+		help:      "Number of packets received by the interface with a CRC error",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "collisions",
+		name:      "ovsdp_interface_collisions",
+		help:      "Number of collisions seen by the interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "rx_missed_errors",
+		name:      "ovsdp_interface_rx_missed_errors",
+		help:      "Number of packets dropped by the DPDK interface because its rx queues were full",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "ovs_rx_qos_drops",
+		name:      "ovsdp_interface_rx_qos_drops",
+		help:      "Number of packets dropped by the ingress policer of the DPDK interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "ovs_tx_qos_drops",
+		name:      "ovsdp_interface_tx_qos_drops",
+		help:      "Number of packets dropped by the egress QoS of the DPDK interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "ovs_tx_failure_drops",
+		name:      "ovsdp_interface_tx_failure_drops",
+		help:      "Number of packets the DPDK interface failed to transmit",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "ovs_tx_mtu_exceeded_drops",
+		name:      "ovsdp_interface_tx_mtu_exceeded_drops",
+		help:      "Number of packets dropped by the DPDK interface for exceeding its MTU",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "ovs_tx_invalid_hwol_drops",
+		name:      "ovsdp_interface_tx_invalid_hwol_drops",
+		help:      "Number of packets dropped by the DPDK interface for invalid offload requests",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "ovs_tx_retries",
+		name:      "ovsdp_interface_tx_retries",
+		help:      "Number of transmit retries of the vhost interface",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "link_state",
+		name:      "ovsdp_interface_link_up",
+		help:      "Whether the link of the interface is up",
+		valueType: prometheus.GaugeValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "mtu",
+		name:      "ovsdp_interface_mtu",
+		help:      "MTU of the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    interfaceLabels,
+	},
+	// Hardware offload
+	{
+		command:   offloadStatsCommand,
+		source:    "Enqueued offloads",
+		name:      "ovsdp_offload_queued",
+		help:      "Number of flow offload requests queued to the offload thread",
+		valueType: prometheus.GaugeValue,
+		labels:    offloadThreadLabels,
+	},
+	{
+		command:   offloadStatsCommand,
+		source:    "Inserted offloads",
+		name:      "ovsdp_offload_inserted",
+		help:      "Number of flows offloaded to hardware by the offload thread",
+		valueType: prometheus.GaugeValue,
+		labels:    offloadThreadLabels,
+	},
+	{
+		command:   offloadStatsCommand,
+		source:    "Cumulative Average latency (us)",
+		name:      "ovsdp_offload_latency_cumulative_avg_microseconds",
+		help:      "Average latency of the offload thread requests since startup",
+		valueType: prometheus.GaugeValue,
+		labels:    offloadThreadLabels,
+	},
+	{
+		command:   offloadStatsCommand,
+		source:    "Cumulative Latency stddev (us)",
+		name:      "ovsdp_offload_latency_cumulative_stddev_microseconds",
+		help:      "Standard deviation of the latency of the offload thread requests since startup",
+		valueType: prometheus.GaugeValue,
+		labels:    offloadThreadLabels,
+	},
+	{
+		command:   offloadStatsCommand,
+		source:    "Exponential Average latency (us)",
+		name:      "ovsdp_offload_latency_exponential_avg_microseconds",
+		help:      "Exponential moving average of the latency of the offload thread requests",
+		valueType: prometheus.GaugeValue,
+		labels:    offloadThreadLabels,
+	},
+	{
+		command:   offloadStatsCommand,
+		source:    "Exponential Latency stddev (us)",
+		name:      "ovsdp_offload_latency_exponential_stddev_microseconds",
+		help:      "Exponential moving standard deviation of the latency of the offload thread requests",
+		valueType: prometheus.GaugeValue,
+		labels:    offloadThreadLabels,
+	},
+	{
+		command:   offloadStatsCommand,
+		source:    "Total Inserted offloads",
+		name:      "ovsdp_offload_flows",
+		help:      "Number of flows offloaded to hardware",
+		valueType: prometheus.GaugeValue,
+	},
+	// DPDK memory
+	{
+		command:   dpdkMempoolCommand,
+		source:    "populated_size",
+		name:      "ovsdp_dpdk_mempool_size",
+		help:      "Number of mbufs in the DPDK mempool",
+		valueType: prometheus.GaugeValue,
+		labels:    mempoolLabels,
+	},
+	{
+		command:   dpdkMempoolCommand,
+		source:    "available",
+		name:      "ovsdp_dpdk_mempool_available",
+		help:      "Number of mbufs available in the DPDK mempool and its caches",
+		valueType: prometheus.GaugeValue,
+		labels:    mempoolLabels,
+	},
+	{
+		command:   dpdkMempoolCommand,
+		source:    "in use",
+		name:      "ovsdp_dpdk_mempool_in_use",
+		help:      "Number of mbufs of the DPDK mempool in use",
+		valueType: prometheus.GaugeValue,
+		labels:    mempoolLabels,
+	},
+	{
+		command:   dpdkMallocCommand,
+		source:    "Heap_size",
+		name:      "ovsdp_dpdk_heap_size_bytes",
+		help:      "Size of the DPDK malloc heap",
+		valueType: prometheus.GaugeValue,
+		labels:    heapLabels,
+	},
+	{
+		command:   dpdkMallocCommand,
+		source:    "Free_size",
+		name:      "ovsdp_dpdk_heap_free_bytes",
+		help:      "Free bytes of the DPDK malloc heap",
+		valueType: prometheus.GaugeValue,
+		labels:    heapLabels,
+	},
+	{
+		command:   dpdkMallocCommand,
+		source:    "Alloc_size",
+		name:      "ovsdp_dpdk_heap_allocated_bytes",
+		help:      "Allocated bytes of the DPDK malloc heap",
+		valueType: prometheus.GaugeValue,
+		labels:    heapLabels,
+	},
+	{
+		command:   dpdkMallocCommand,
+		source:    "Greatest_free_size",
+		name:      "ovsdp_dpdk_heap_greatest_free_bytes",
+		help:      "Size of the largest free block of the DPDK malloc heap",
+		valueType: prometheus.GaugeValue,
+		labels:    heapLabels,
+	},
+	{
+		command:   dpdkMallocCommand,
+		source:    "Alloc_count",
+		name:      "ovsdp_dpdk_heap_allocations",
+		help:      "Number of allocated blocks of the DPDK malloc heap",
+		valueType: prometheus.GaugeValue,
+		labels:    heapLabels,
+	},
+	{
+		command:   dpdkMallocCommand,
+		source:    "Free_count",
+		name:      "ovsdp_dpdk_heap_free_blocks",
+		help:      "Number of free blocks of the DPDK malloc heap",
+		valueType: prometheus.GaugeValue,
+		labels:    heapLabels,
+	},
+	// Bonds
+	{
+		command:   bondCommand,
+		source:    "mode",
+		name:      "ovsdp_bond_info",
+		help:      "Mode of the bond, always 1",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"bond", "mode"},
+	},
+	{
+		command:   bondCommand,
+		source:    "enabled",
+		name:      "ovsdp_bond_member_enabled",
+		help:      "Whether the bond member is enabled to send and receive traffic",
+		valueType: prometheus.GaugeValue,
+		labels:    bondMemberLabels,
+	},
+	{
+		command:   bondCommand,
+		source:    "may_enable",
+		name:      "ovsdp_bond_member_may_enable",
+		help:      "Whether the bond member may be enabled, its link being up and LACP agreeing",
+		valueType: prometheus.GaugeValue,
+		labels:    bondMemberLabels,
+	},
+	{
+		command:   bondCommand,
+		source:    "active",
+		name:      "ovsdp_bond_member_active",
+		help:      "Whether the bond member is the active member, receiving broadcast and multicast traffic",
+		valueType: prometheus.GaugeValue,
+		labels:    bondMemberLabels,
+	},
+	{
+		command:   bondCommand,
+		source:    "hash load",
+		name:      "ovsdp_bond_hash_load_bytes",
+		help:      "Bytes recently sent through the bond hash bucket by the member it is assigned to",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"bond", "member", "hash"},
+	},
+	{
+		command:   lacpCommand,
+		source:    "negotiated",
+		name:      "ovsdp_lacp_negotiated",
+		help:      "Whether LACP negotiation succeeded with the partner of the bond",
+		valueType: prometheus.GaugeValue,
+		labels:    bondLabels,
+	},
+	{
+		command:   lacpCommand,
+		source:    "current",
+		name:      "ovsdp_lacp_member_current",
+		help:      "Whether the bond member received LACP PDUs from its partner before they expired",
+		valueType: prometheus.GaugeValue,
+		labels:    bondMemberLabels,
+	},
+	{
+		command:   lacpCommand,
+		source:    "attached",
+		name:      "ovsdp_lacp_member_attached",
+		help:      "Whether the bond member is attached to the LACP aggregate",
+		valueType: prometheus.GaugeValue,
+		labels:    bondMemberLabels,
+	},
+	{
+		command:   lacpCommand,
+		source:    "partner",
+		name:      "ovsdp_lacp_partner_info",
+		help:      "LACP partner of the bond member, always 1",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"bond", "member", "partner_sys_id", "partner_port_id", "partner_key"},
+	},
+	// BFD and CFM
+	{
+		command:   bfdCommand,
+		source:    "Local Session State",
+		name:      "ovsdp_bfd_local_state",
+		help:      "Local BFD session state of the interface: 0 admin_down, 1 down, 2 init, 3 up",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "Remote Session State",
+		name:      "ovsdp_bfd_remote_state",
+		help:      "Remote BFD session state of the interface: 0 admin_down, 1 down, 2 init, 3 up",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "Local Diagnostic",
+		name:      "ovsdp_bfd_local_diagnostic",
+		help:      "RFC 5880 diagnostic code of the last local BFD session state change of the interface, 0 if none",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "Remote Diagnostic",
+		name:      "ovsdp_bfd_remote_diagnostic",
+		help:      "RFC 5880 diagnostic code of the last remote BFD session state change of the interface, 0 if none",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "Forwarding",
+		name:      "ovsdp_bfd_forwarding",
+		help:      "Whether BFD considers the interface fit to forward traffic",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "Detect Multiplier",
+		name:      "ovsdp_bfd_detect_multiplier",
+		help:      "Local BFD detect multiplier of the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "Remote Detect Multiplier",
+		name:      "ovsdp_bfd_remote_detect_multiplier",
+		help:      "Remote BFD detect multiplier of the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "TX Interval",
+		name:      "ovsdp_bfd_tx_interval_seconds",
+		help:      "Agreed interval between the BFD control packets sent on the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "RX Interval",
+		name:      "ovsdp_bfd_rx_interval_seconds",
+		help:      "Agreed interval between the BFD control packets received on the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   bfdCommand,
+		source:    "detect interval",
+		name:      "ovsdp_bfd_detect_interval_seconds",
+		help:      "Time without BFD control packets received on the interface after which the session goes down",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   cfmCommand,
+		source:    "fault",
+		name:      "ovsdp_cfm_fault",
+		help:      "Whether CFM detected a fault on the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   cfmCommand,
+		source:    "average health",
+		name:      "ovsdp_cfm_health",
+		help:      "Percentage of CCMs received from the remote MP of the interface, averaged over recent fault checks",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   cfmCommand,
+		source:    "opstate",
+		name:      "ovsdp_cfm_opstate_up",
+		help:      "Whether the local CFM operational state of the interface is up",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   cfmCommand,
+		source:    "remote_opstate",
+		name:      "ovsdp_cfm_remote_opstate_up",
+		help:      "Whether the remote CFM operational state of the interface is up",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   cfmCommand,
+		source:    "interval",
+		name:      "ovsdp_cfm_interval_seconds",
+		help:      "Interval between the CCMs sent on the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   cfmCommand,
+		source:    "remote mps",
+		name:      "ovsdp_cfm_remote_mps",
+		help:      "Number of remote maintenance points seen by CFM on the interface",
+		valueType: prometheus.GaugeValue,
+		labels:    sessionLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "bfd flap_count",
+		name:      "ovsdp_bfd_flaps",
+		help:      "Number of BFD forwarding state changes of the interface, from the OVSDB Interface table with its labels, absent if -ovs.db is empty",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	{
+		command:   interfaceCommand,
+		source:    "cfm_flap_count",
+		name:      "ovsdp_cfm_flaps",
+		help:      "Number of CFM fault state changes of the interface, from the OVSDB Interface table with its labels, absent if -ovs.db is empty",
+		valueType: prometheus.CounterValue,
+		labels:    interfaceLabels,
+	},
+	// Tunnels
+	{
+		command:   tunnelPortsCommand,
+		source:    "ref_cnt",
+		name:      "ovsdp_tunnel_port_references",
+		help:      "Sum of the reference counts of the tunnel listening ports of the type, one per tunnel port using them",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"type"},
+	},
+	{
+		command:   tunnelNeighborsCommand,
+		source:    "neighbors",
+		name:      "ovsdp_tunnel_neighbors",
+		help:      "Number of tunnel neighbor cache entries by bridge and state",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"bridge", "state"},
+	},
+	{
+		command:   tunnelNeighborsCommand,
+		source:    "neighbor",
+		name:      "ovsdp_tunnel_neighbor",
+		help:      "Tunnel neighbor cache entry, always 1",
+		valueType: prometheus.GaugeValue,
+		labels:    []string{"bridge", "ip", "mac", "state"},
+	},
+	// MAC learning
+	{
+		command:   fdbStatsCommand,
+		source:    "current",
+		name:      "ovsdp_fdb_entries",
+		help:      "Number of entries in the MAC learning table of the bridge",
+		valueType: prometheus.GaugeValue,
+		labels:    bridgeLabels,
+	},
+	{
+		command:   fdbStatsCommand,
+		source:    "maximum",
+		name:      "ovsdp_fdb_max_entries",
+		help:      "Maximum number of entries in the MAC learning table of the bridge",
+		valueType: prometheus.GaugeValue,
+		labels:    bridgeLabels,
+	},
+	{
+		command:   fdbStatsCommand,
+		source:    "static",
+		name:      "ovsdp_fdb_static_entries",
+		help:      "Number of static entries in the MAC learning table of the bridge",
+		valueType: prometheus.GaugeValue,
+		labels:    bridgeLabels,
+	},
+	{
+		command:   fdbStatsCommand,
+		source:    "learned",
+		name:      "ovsdp_fdb_learned",
+		help:      "Number of MAC entries learned by the bridge",
+		valueType: prometheus.CounterValue,
+		labels:    bridgeLabels,
+	},
+	{
+		command:   fdbStatsCommand,
+		source:    "expired",
+		name:      "ovsdp_fdb_expired",
+		help:      "Number of MAC entries of the bridge that expired",
+		valueType: prometheus.CounterValue,
+		labels:    bridgeLabels,
+	},
+	{
+		command:   fdbStatsCommand,
+		source:    "evicted",
+		name:      "ovsdp_fdb_evicted",
+		help:      "Number of MAC entries of the bridge evicted because its table was full",
+		valueType: prometheus.CounterValue,
+		labels:    bridgeLabels,
+	},
+	{
+		command:   fdbStatsCommand,
+		source:    "moved",
+		name:      "ovsdp_fdb_moved",
+		help:      "Number of MAC entries of the bridge that moved to another port",
+		valueType: prometheus.CounterValue,
+		labels:    bridgeLabels,
+	},
+	// OpenFlow, per bridge
+	{
+		command:   dumpTablesCommand,
+		source:    "active",
+		name:      "ovsdp_openflow_table_active_flows",
+		help:      "Number of flows in the OpenFlow table",
+		valueType: prometheus.GaugeValue,
+		labels:    openFlowTableLabels,
+	},
+	{
+		command:   dumpTablesCommand,
+		source:    "lookup",
+		name:      "ovsdp_openflow_table_lookups",
+		help:      "Number of packets looked up in the OpenFlow table",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowTableLabels,
+	},
+	{
+		command:   dumpTablesCommand,
+		source:    "matched",
+		name:      "ovsdp_openflow_table_matches",
+		help:      "Number of packets that matched a flow of the OpenFlow table",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowTableLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "rx pkts",
+		name:      "ovsdp_openflow_port_rx_packets",
+		help:      "Number of packets received by the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "rx bytes",
+		name:      "ovsdp_openflow_port_rx_bytes",
+		help:      "Number of bytes received by the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "rx drop",
+		name:      "ovsdp_openflow_port_rx_dropped",
+		help:      "Number of rx packets dropped by the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "rx errs",
+		name:      "ovsdp_openflow_port_rx_errors",
+		help:      "Number of rx errors of the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "tx pkts",
+		name:      "ovsdp_openflow_port_tx_packets",
+		help:      "Number of packets transmitted by the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "tx bytes",
+		name:      "ovsdp_openflow_port_tx_bytes",
+		help:      "Number of bytes transmitted by the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "tx drop",
+		name:      "ovsdp_openflow_port_tx_dropped",
+		help:      "Number of tx packets dropped by the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   dumpPortsCommand,
+		source:    "tx errs",
+		name:      "ovsdp_openflow_port_tx_errors",
+		help:      "Number of tx errors of the OpenFlow port",
+		valueType: prometheus.CounterValue,
+		labels:    openFlowPortLabels,
+	},
+	{
+		command:   meterStatsCommand,
+		source:    "flow_count",
+		name:      "ovsdp_meter_flows",
+		help:      "Number of flows using the OpenFlow meter",
+		valueType: prometheus.GaugeValue,
+		labels:    meterLabels,
+	},
+	{
+		command:   meterStatsCommand,
+		source:    "packet_in_count",
+		name:      "ovsdp_meter_packets",
+		help:      "Number of packets processed by the OpenFlow meter",
+		valueType: prometheus.CounterValue,
+		labels:    meterLabels,
+	},
+	{
+		command:   meterStatsCommand,
+		source:    "byte_in_count",
+		name:      "ovsdp_meter_bytes",
+		help:      "Number of bytes processed by the OpenFlow meter",
+		valueType: prometheus.CounterValue,
+		labels:    meterLabels,
+	},
+	{
+		command:   meterStatsCommand,
+		source:    "packet_count",
+		name:      "ovsdp_meter_band_packets",
+		help:      "Number of packets over the rate of the meter band, dropped by drop bands",
+		valueType: prometheus.CounterValue,
+		labels:    meterBandLabels,
+	},
+	{
+		command:   meterStatsCommand,
+		source:    "byte_count",
+		name:      "ovsdp_meter_band_bytes",
+		help:      "Number of bytes over the rate of the meter band, dropped by drop bands",
+		valueType: prometheus.CounterValue,
+		labels:    meterBandLabels,
+	},
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// PMDRxqMetric holds the rx queues polled by a PMD thread, from
+// pmd-rxq-show.
+type PMDRxqMetric struct {
+	NumaID string
+	CoreID string
+	// Values hold "isolated" and "overhead" when printed.
+	Values map[string]float64
+	Queues []RxqMetric
+}
+
+// RxqMetric is an rx queue polled by a PMD thread.
+type RxqMetric struct {
+	Port    string
+	QueueID string
+	// Values hold "enabled" and "pmd usage" when printed. The usage isn't
+	// available until the PMD has run a full measurement interval.
+	Values map[string]float64
+}
+
+// pmdRxqSamples returns the values of a pmd-rxq-show registry metric.
+func (metrics *OvsMetric) pmdRxqSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, pmd := range metrics.PMDRxqs {
+		if v, ok := pmd.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{pmd.NumaID, pmd.CoreID}})
+		}
+		for _, queue := range pmd.Queues {
+			if v, ok := queue.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{pmd.NumaID, pmd.CoreID, queue.Port, queue.QueueID}})
+			}
+		}
+	}
+	return samples
+}
+
+func parsePMDRxq(metrics *OvsMetric, pmdRxq string) {
+	isolatedRegexp := regexp.MustCompile(`(?m)^[ \t]*isolated\s*:\s*(true|false)`)
+	overheadRegexp := regexp.MustCompile(`(?m)^[ \t]*overhead:\s*(\d+(?:\.\d+)?)\s*%`)
+	// e.g. "port: dpdk0   queue-id:  0 (enabled)   pmd usage: 13 %"
+	queueRegexp := regexp.MustCompile(`(?m)^[ \t]*port:\s*(\S+)\s+queue-id:\s*(\d+)(?:\s+\((enabled|disabled)\))?(?:\s+pmd usage:\s*(?:(\d+(?:\.\d+)?)\s*%|NOT AVAIL))?`)
+
+	metrics.PMDRxqs = nil
+	for _, section := range splitPMDSections(pmdRxq) {
+		if section.thread != "pmd" {
+			continue
+		}
+		pmd := PMDRxqMetric{NumaID: section.numaID, CoreID: section.coreID, Values: make(map[string]float64)}
+
+		if match := isolatedRegexp.FindStringSubmatch(section.body); match != nil {
+			pmd.Values["isolated"] = boolValue(match[1] == "true")
+		}
+		if match := overheadRegexp.FindStringSubmatch(section.body); match != nil {
+			v, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				metrics.parseFailure(pmdRxqCommand, "overhead")
+			} else {
+				pmd.Values["overhead"] = v
+			}
+		}
+
+		for _, match := range queueRegexp.FindAllStringSubmatch(section.body, -1) {
+			queue := RxqMetric{Port: match[1], QueueID: match[2], Values: make(map[string]float64)}
+			if match[3] != "" {
+				queue.Values["enabled"] = boolValue(match[3] == "enabled")
+			}
+			if match[4] != "" {
+				v, err := strconv.ParseFloat(match[4], 64)
+				if err != nil {
+					metrics.parseFailure(pmdRxqCommand, "pmd usage")
+				} else {
+					queue.Values["pmd usage"] = v
+				}
+			}
+			pmd.Queues = append(pmd.Queues, queue)
+		}
+
+		metrics.PMDRxqs = append(metrics.PMDRxqs, pmd)
+	}
+}
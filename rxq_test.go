@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parsePMDRxq(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `pmd thread numa_id 0 core_id 2:
+  isolated : false
+  port: dpdk0             queue-id:  0 (enabled)   pmd usage: 13 %
+  port: vhu7f3a2c1e-4b    queue-id:  1 (disabled)  pmd usage: NOT AVAIL
+  overhead:  4 %
+pmd thread numa_id 1 core_id 34:
+  isolated : true
+  port: dpdk1             queue-id:  0 (enabled)   pmd usage: 87 %
+  overhead:  0 %`,
+			metric: OvsMetric{
+				PMDRxqs: []PMDRxqMetric{
+					{
+						NumaID: "0",
+						CoreID: "2",
+						Values: map[string]float64{"isolated": 0, "overhead": 4},
+						Queues: []RxqMetric{
+							{Port: "dpdk0", QueueID: "0", Values: map[string]float64{"enabled": 1, "pmd usage": 13}},
+							{Port: "vhu7f3a2c1e-4b", QueueID: "1", Values: map[string]float64{"enabled": 0}},
+						},
+					},
+					{
+						NumaID: "1",
+						CoreID: "34",
+						Values: map[string]float64{"isolated": 1, "overhead": 0},
+						Queues: []RxqMetric{
+							{Port: "dpdk1", QueueID: "0", Values: map[string]float64{"enabled": 1, "pmd usage": 87}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "without queue state and overhead",
+			output: `pmd thread numa_id 0 core_id 1:
+  isolated : false
+  port: dpdk0             queue-id:  0  pmd usage:  5 %`,
+			metric: OvsMetric{
+				PMDRxqs: []PMDRxqMetric{
+					{
+						NumaID: "0",
+						CoreID: "1",
+						Values: map[string]float64{"isolated": 0},
+						Queues: []RxqMetric{
+							{Port: "dpdk0", QueueID: "0", Values: map[string]float64{"pmd usage": 5}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parsePMDRxq(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}
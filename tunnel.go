@@ -0,0 +1,104 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TunnelPortMetric is a tunnel listening port from tnl/ports/show, shared by
+// the tunnel ports of its type.
+type TunnelPortMetric struct {
+	Port string
+	// Type is derived from the port name, e.g. geneve for genev_sys_6081.
+	Type string
+	// Values hold "ref_cnt", the number of tunnel ports using the listening
+	// port.
+	Values map[string]float64
+}
+
+// TunnelNeighbor is an entry of the tunnel neighbor cache from
+// tnl/neigh/show.
+type TunnelNeighbor struct {
+	IP     string
+	MAC    string
+	Bridge string
+	// State is "stale" for expired entries, or the lowercased flag printed
+	// after the bridge, "reachable" otherwise.
+	State string
+}
+
+// tunnelSamples returns the values of a tnl/ports/show or tnl/neigh/show
+// registry metric, aggregated by type and by bridge and state.
+func (metrics *OvsMetric) tunnelSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	index := make(map[string]int)
+	add := func(v float64, labels ...string) {
+		key := strings.Join(labels, "\x00")
+		if i, ok := index[key]; ok {
+			samples[i].Value += v
+			return
+		}
+		index[key] = len(samples)
+		samples = append(samples, Sample{Value: v, Labels: labels})
+	}
+
+	switch spec.command {
+	case tunnelPortsCommand:
+		for _, port := range metrics.TunnelPorts {
+			if v, ok := port.Values[spec.source]; ok {
+				add(v, port.Type)
+			}
+		}
+	case tunnelNeighborsCommand:
+		for _, neighbor := range metrics.TunnelNeighbors {
+			if spec.source == "neighbor" {
+				add(1, neighbor.Bridge, neighbor.IP, neighbor.MAC, neighbor.State)
+			} else {
+				add(1, neighbor.Bridge, neighbor.State)
+			}
+		}
+	}
+	return samples
+}
+
+func parseTunnelPorts(metrics *OvsMetric, tunnelPorts string) {
+	// e.g. "genev_sys_6081 (6081) ref_cnt=1"
+	portRegexp := regexp.MustCompile(`(?m)^(\S+) \(\d+\) ref_cnt=(\d+)`)
+
+	metrics.TunnelPorts = nil
+	for _, match := range portRegexp.FindAllStringSubmatch(tunnelPorts, -1) {
+		port := TunnelPortMetric{Port: match[1], Type: tunnelType(match[1]), Values: make(map[string]float64)}
+		v, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			metrics.parseFailure(tunnelPortsCommand, "ref_cnt")
+		} else {
+			port.Values["ref_cnt"] = v
+		}
+		metrics.TunnelPorts = append(metrics.TunnelPorts, port)
+	}
+}
+
+// tunnelType returns the tunnel type of a listening port named after it,
+// like vxlan_sys_4789 or genev_sys_6081.
+func tunnelType(port string) string {
+	kind, _, _ := strings.Cut(port, "_")
+	if kind == "genev" {
+		return "geneve"
+	}
+	return kind
+}
+
+func parseTunnelNeighbors(metrics *OvsMetric, tunnelNeighbors string) {
+	// e.g. "10.0.0.2      aa:bb:cc:dd:ee:ff   br-phy STALE"
+	neighborRegexp := regexp.MustCompile(`(?m)^([0-9a-fA-F.:]+)\s+([0-9a-fA-F]{2}(?::[0-9a-fA-F]{2}){5})\s+(\S+)(?:\s+(\S+))?[ \t]*$`)
+
+	metrics.TunnelNeighbors = nil
+	for _, match := range neighborRegexp.FindAllStringSubmatch(tunnelNeighbors, -1) {
+		neighbor := TunnelNeighbor{IP: match[1], MAC: match[2], Bridge: match[3], State: "reachable"}
+		if match[4] != "" {
+			neighbor.State = strings.ToLower(match[4])
+		}
+		metrics.TunnelNeighbors = append(metrics.TunnelNeighbors, neighbor)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_parseTunnelPorts(t *testing.T) {
+	var ovsMetric OvsMetric
+	parseTunnelPorts(&ovsMetric, `Listening ports:
+genev_sys_6081 (6081) ref_cnt=3
+vxlan_sys_4789 (4789) ref_cnt=1
+vxlan_sys_4790 (4790) ref_cnt=2
+gre_sys (5) ref_cnt=1
+`)
+
+	expected := []TunnelPortMetric{
+		{Port: "genev_sys_6081", Type: "geneve", Values: map[string]float64{"ref_cnt": 3}},
+		{Port: "vxlan_sys_4789", Type: "vxlan", Values: map[string]float64{"ref_cnt": 1}},
+		{Port: "vxlan_sys_4790", Type: "vxlan", Values: map[string]float64{"ref_cnt": 2}},
+		{Port: "gre_sys", Type: "gre", Values: map[string]float64{"ref_cnt": 1}},
+	}
+	diff := cmp.Diff(ovsMetric.TunnelPorts, expected)
+	if diff != "" {
+		t.Errorf("Ports are different:\n%s", diff)
+	}
+
+	// Two vxlan listening ports referenced 3 times
+	samples := ovsMetric.tunnelSamples(metricSpec{command: tunnelPortsCommand, source: "ref_cnt"})
+	expectedSamples := []Sample{
+		{Value: 3, Labels: []string{"geneve"}},
+		{Value: 3, Labels: []string{"vxlan"}},
+		{Value: 1, Labels: []string{"gre"}},
+	}
+	diff = cmp.Diff(samples, expectedSamples)
+	if diff != "" {
+		t.Errorf("Samples are different:\n%s", diff)
+	}
+}
+
+func Test_parseTunnelNeighbors(t *testing.T) {
+	var ovsMetric OvsMetric
+	parseTunnelNeighbors(&ovsMetric, `IP                                            MAC                 Bridge
+==========================================================================
+10.0.0.2                                      aa:bb:cc:dd:ee:01   br-phy
+10.0.0.3                                      aa:bb:cc:dd:ee:02   br-phy STALE
+fe80::1                                       aa:bb:cc:dd:ee:03   br-phy
+10.1.0.2                                      aa:bb:cc:dd:ee:04   br-ex
+`)
+
+	expected := []TunnelNeighbor{
+		{IP: "10.0.0.2", MAC: "aa:bb:cc:dd:ee:01", Bridge: "br-phy", State: "reachable"},
+		{IP: "10.0.0.3", MAC: "aa:bb:cc:dd:ee:02", Bridge: "br-phy", State: "stale"},
+		{IP: "fe80::1", MAC: "aa:bb:cc:dd:ee:03", Bridge: "br-phy", State: "reachable"},
+		{IP: "10.1.0.2", MAC: "aa:bb:cc:dd:ee:04", Bridge: "br-ex", State: "reachable"},
+	}
+	diff := cmp.Diff(ovsMetric.TunnelNeighbors, expected)
+	if diff != "" {
+		t.Errorf("Neighbors are different:\n%s", diff)
+	}
+
+	samples := ovsMetric.tunnelSamples(metricSpec{command: tunnelNeighborsCommand, source: "neighbors"})
+	expectedSamples := []Sample{
+		{Value: 2, Labels: []string{"br-phy", "reachable"}},
+		{Value: 1, Labels: []string{"br-phy", "stale"}},
+		{Value: 1, Labels: []string{"br-ex", "reachable"}},
+	}
+	diff = cmp.Diff(samples, expectedSamples)
+	if diff != "" {
+		t.Errorf("Samples are different:\n%s", diff)
+	}
+}
+
+func Test_ovsDPCollectorTunnelNeighbors(t *testing.T) {
+	ctl := fakeAppctl{
+		"tnl/neigh/show": `IP                                            MAC                 Bridge
+==========================================================================
+10.0.0.2                                      aa:bb:cc:dd:ee:01   br-phy
+10.0.0.3                                      aa:bb:cc:dd:ee:02   br-phy STALE
+`,
+	}
+
+	tests := []struct {
+		name            string
+		tunnelNeighbors bool
+		expected        int
+	}{
+		{name: "disabled", tunnelNeighbors: false, expected: 0},
+		{name: "enabled", tunnelNeighbors: true, expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := newOvsDPCollector(ctl, nil, nil, time.Second, coverageFilter{}, 0, nil, tt.tunnelNeighbors)
+			if count := testutil.CollectAndCount(collector, "ovsdp_tunnel_neighbor"); count != tt.expected {
+				t.Errorf("Expected %d neighbors, got %d", tt.expected, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unixctlClient runs commands over the unixctl socket of an OVS daemon using
+// JSON-RPC, the same way ovs-appctl does, without forking a process.
+type unixctlClient struct {
+	// rundir holds the daemon pidfile and unixctl socket, usually
+	// /var/run/openvswitch.
+	rundir string
+	// target is the daemon name, e.g. ovs-vswitchd.
+	target string
+	// fallback runs the command when the socket can't be reached. It may
+	// be nil.
+	fallback appctl
+}
+
+type unixctlRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int      `json:"id"`
+}
+
+type unixctlResponse struct {
+	Result *string          `json:"result"`
+	Error  *json.RawMessage `json:"error"`
+	ID     int              `json:"id"`
+}
+
+// socketPath resolves the unixctl socket from the daemon pidfile, as
+// <rundir>/<target>.<pid>.ctl.
+func (client unixctlClient) socketPath() (string, error) {
+	pidfile := filepath.Join(client.rundir, client.target+".pid")
+	content, err := os.ReadFile(pidfile)
+	if err != nil {
+		return "", err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return "", fmt.Errorf("invalid pidfile %s: %w", pidfile, err)
+	}
+	return filepath.Join(client.rundir, fmt.Sprintf("%s.%d.ctl", client.target, pid)), nil
+}
+
+func (client unixctlClient) run(ctx context.Context, command string, args ...string) (string, error) {
+	conn, err := client.dial(ctx)
+	if err != nil {
+		if client.fallback != nil && ctx.Err() == nil {
+			return client.fallback.run(ctx, command, args...)
+		}
+		return "", err
+	}
+	defer conn.Close()
+
+	// Unblock reads and writes when ctx is done.
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if args == nil {
+		args = []string{}
+	}
+	if err := json.NewEncoder(conn).Encode(unixctlRequest{Method: command, Params: args}); err != nil {
+		return "", err
+	}
+
+	var response unixctlResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return "", err
+	}
+	if response.Error != nil && string(*response.Error) != "null" {
+		var message string
+		if json.Unmarshal(*response.Error, &message) != nil {
+			message = string(*response.Error)
+		}
+		return "", errors.New(strings.TrimSpace(message))
+	}
+	if response.Result == nil {
+		return "", nil
+	}
+	return *response.Result, nil
+}
+
+func (client unixctlClient) dial(ctx context.Context) (net.Conn, error) {
+	path, err := client.socketPath()
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "unix", path)
+}
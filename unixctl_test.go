@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeUnixctlServer serves unixctl JSON-RPC requests on
+// <rundir>/ovs-vswitchd.<pid>.ctl, answering with the output of commands or
+// an error for unknown ones.
+func fakeUnixctlServer(t *testing.T, commands map[string]string) string {
+	t.Helper()
+
+	rundir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rundir, "ovs-vswitchd.pid"), []byte("4242\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("unix", filepath.Join(rundir, "ovs-vswitchd.4242.ctl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var request unixctlRequest
+				if err := json.NewDecoder(conn).Decode(&request); err != nil {
+					return
+				}
+				response := map[string]interface{}{"id": request.ID, "result": nil, "error": nil}
+				if output, ok := commands[strings.Join(append([]string{request.Method}, request.Params...), " ")]; ok {
+					response["result"] = output
+				} else {
+					response["error"] = "\"" + request.Method + "\" is not a valid command\n"
+				}
+				json.NewEncoder(conn).Encode(response)
+			}()
+		}
+	}()
+
+	return rundir
+}
+
+func Test_unixctlClient(t *testing.T) {
+	rundir := fakeUnixctlServer(t, map[string]string{
+		"coverage/show":              "netlink_sent   0.0/sec     0.000/sec        0.0000/sec   total: 5\n",
+		"dpctl/ct-get-limits zone=1": "default limit=0\nzone=1,limit=10,count=2\n",
+	})
+
+	tests := []struct {
+		name    string
+		client  unixctlClient
+		command []string
+		output  string
+		err     string
+	}{
+		{
+			name:    "result",
+			client:  unixctlClient{rundir: rundir, target: "ovs-vswitchd"},
+			command: []string{"coverage/show"},
+			output:  "netlink_sent   0.0/sec     0.000/sec        0.0000/sec   total: 5\n",
+		},
+		{
+			name:    "params",
+			client:  unixctlClient{rundir: rundir, target: "ovs-vswitchd"},
+			command: []string{"dpctl/ct-get-limits", "zone=1"},
+			output:  "default limit=0\nzone=1,limit=10,count=2\n",
+		},
+		{
+			name:    "error",
+			client:  unixctlClient{rundir: rundir, target: "ovs-vswitchd"},
+			command: []string{"dpctl/bogus"},
+			err:     `"dpctl/bogus" is not a valid command`,
+		},
+		{
+			name:    "no socket",
+			client:  unixctlClient{rundir: t.TempDir(), target: "ovs-vswitchd"},
+			command: []string{"coverage/show"},
+			err:     "no such file or directory",
+		},
+		{
+			name:    "fallback",
+			client:  unixctlClient{rundir: t.TempDir(), target: "ovs-vswitchd", fallback: fakeAppctl{"coverage/show": "fallback output"}},
+			command: []string{"coverage/show"},
+			output:  "fallback output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.client.run(context.Background(), tt.command[0], tt.command[1:]...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if output != tt.output {
+				t.Errorf("Outputs are different: got %q, want %q", output, tt.output)
+			}
+		})
+	}
+}
+
+func Test_unixctlClientTimeout(t *testing.T) {
+	rundir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rundir, "ovs-vswitchd.pid"), []byte("4242\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("unix", filepath.Join(rundir, "ovs-vswitchd.4242.ctl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	// Accept connections but never answer, like a wedged ovs-vswitchd.
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := unixctlClient{rundir: rundir, target: "ovs-vswitchd"}
+	start := time.Now()
+	if _, err := client.run(ctx, "coverage/show"); err == nil {
+		t.Error("Expected an error from a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Command returned after %s, expected it to be cancelled", elapsed)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// UpcallMetric holds the upcall handling state of a datapath from
+// upcall/show.
+type UpcallMetric struct {
+	Datapath string
+	// Values hold "flows", "avg flows", "max flows", "flow limit",
+	// "offloaded flows", "dump duration" in seconds and "ufid enabled" when
+	// printed.
+	Values       map[string]float64
+	Revalidators []RevalidatorMetric
+}
+
+// RevalidatorMetric is a revalidator thread of a datapath.
+type RevalidatorMetric struct {
+	ID string
+	// Values hold "keys", the number of udpif keys the revalidator owns.
+	Values map[string]float64
+}
+
+// upcallSamples returns the values of an upcall/show registry metric.
+func (metrics *OvsMetric) upcallSamples(spec metricSpec) []Sample {
+	var samples []Sample
+	for _, datapath := range metrics.Upcalls {
+		if v, ok := datapath.Values[spec.source]; ok {
+			samples = append(samples, Sample{Value: v, Labels: []string{datapath.Datapath}})
+		}
+		for _, revalidator := range datapath.Revalidators {
+			if v, ok := revalidator.Values[spec.source]; ok {
+				samples = append(samples, Sample{Value: v, Labels: []string{datapath.Datapath, revalidator.ID}})
+			}
+		}
+	}
+	return samples
+}
+
+func parseUpcall(metrics *OvsMetric, upcall string) {
+	headerRegexp := regexp.MustCompile(`(?m)^(\S+):[ \t]*$`)
+	// e.g. "flows         : (current 2) (avg 2) (max 10) (limit 200000)"
+	flowsRegexp := regexp.MustCompile(`(?m)^[ \t]*flows\s*:\s*\(current (\d+)\)\s*\(avg (\d+)\)\s*\(max (\d+)\)\s*\(limit (\d+)\)`)
+	offloadedRegexp := regexp.MustCompile(`(?m)^[ \t]*offloaded flows\s*:\s*(\d+)`)
+	dumpDurationRegexp := regexp.MustCompile(`(?m)^[ \t]*dump duration\s*:\s*(\d+)ms`)
+	ufidRegexp := regexp.MustCompile(`(?m)^[ \t]*ufid enabled\s*:\s*(true|false)`)
+	// e.g. "4: (keys 2)"
+	revalidatorRegexp := regexp.MustCompile(`(?m)^[ \t]*(\d+): \(keys (\d+)\)`)
+
+	metrics.Upcalls = nil
+	headers := headerRegexp.FindAllStringSubmatchIndex(upcall, -1)
+	for i, header := range headers {
+		end := len(upcall)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		body := upcall[header[1]:end]
+		datapath := UpcallMetric{Datapath: upcall[header[2]:header[3]], Values: make(map[string]float64)}
+
+		if match := flowsRegexp.FindStringSubmatch(body); match != nil {
+			for j, source := range []string{"flows", "avg flows", "max flows", "flow limit"} {
+				v, err := strconv.ParseFloat(match[j+1], 64)
+				if err != nil {
+					metrics.parseFailure(upcallCommand, source)
+					continue
+				}
+				datapath.Values[source] = v
+			}
+		}
+		if match := offloadedRegexp.FindStringSubmatch(body); match != nil {
+			v, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				metrics.parseFailure(upcallCommand, "offloaded flows")
+			} else {
+				datapath.Values["offloaded flows"] = v
+			}
+		}
+		if match := dumpDurationRegexp.FindStringSubmatch(body); match != nil {
+			v, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				metrics.parseFailure(upcallCommand, "dump duration")
+			} else {
+				datapath.Values["dump duration"] = v / 1000
+			}
+		}
+		if match := ufidRegexp.FindStringSubmatch(body); match != nil {
+			datapath.Values["ufid enabled"] = boolValue(match[1] == "true")
+		}
+
+		for _, match := range revalidatorRegexp.FindAllStringSubmatch(body, -1) {
+			v, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				metrics.parseFailure(upcallCommand, "keys")
+				continue
+			}
+			datapath.Revalidators = append(datapath.Revalidators, RevalidatorMetric{ID: match[1], Values: map[string]float64{"keys": v}})
+		}
+
+		metrics.Upcalls = append(metrics.Upcalls, datapath)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseUpcall(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		metric OvsMetric
+	}{
+		{
+			name: "output1",
+			output: `netdev@ovs-netdev:
+  flows         : (current 2) (avg 3) (max 10) (limit 200000)
+  dump duration : 12ms
+  ufid enabled : true
+
+  4: (keys 2)
+  5: (keys 0)
+system@ovs-system:
+  flows         : (current 0) (avg 0) (max 0) (limit 10000)
+  offloaded flows : 0
+  dump duration : 1ms
+  ufid enabled : false
+
+  6: (keys 0)
+`,
+			metric: OvsMetric{
+				Upcalls: []UpcallMetric{
+					{
+						Datapath: "netdev@ovs-netdev",
+						Values:   map[string]float64{"flows": 2, "avg flows": 3, "max flows": 10, "flow limit": 200000, "dump duration": 0.012, "ufid enabled": 1},
+						Revalidators: []RevalidatorMetric{
+							{ID: "4", Values: map[string]float64{"keys": 2}},
+							{ID: "5", Values: map[string]float64{"keys": 0}},
+						},
+					},
+					{
+						Datapath: "system@ovs-system",
+						Values:   map[string]float64{"flows": 0, "avg flows": 0, "max flows": 0, "flow limit": 10000, "offloaded flows": 0, "dump duration": 0.001, "ufid enabled": 0},
+						Revalidators: []RevalidatorMetric{
+							{ID: "6", Values: map[string]float64{"keys": 0}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ovsMetric OvsMetric
+			parseUpcall(&ovsMetric, tt.output)
+
+			diff := cmp.Diff(ovsMetric, tt.metric)
+			if diff != "" {
+				t.Errorf("Structs are different:\n%s", diff)
+			}
+		})
+	}
+}